@@ -0,0 +1,169 @@
+// Package audit records who did what to which resource. Entries are written
+// through store.AuditStore, which is append-only.
+package audit
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+	"time"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionRegister    = "auth.register"
+	ActionLogin       = "auth.login"
+	ActionLoginFailed = "auth.login_failed"
+	ActionLogout      = "auth.logout"
+
+	ActionEmailVerified = "auth.email_verified"
+	ActionPasswordReset = "auth.password_reset"
+	ActionAccountLocked = "auth.account_locked"
+
+	ActionMFAEnable             = "mfa.enable"
+	ActionMFADisable            = "mfa.disable"
+	ActionMFAReset              = "mfa.reset"
+	ActionMFARecoveryRegenerate = "mfa.recovery_codes_regenerate"
+	ActionMFARecoveryUsed       = "mfa.recovery_code_used"
+	ActionMFAPolicyUpdate       = "mfa.policy_update"
+
+	ActionAPITokenCreate = "api_token.create"
+	ActionAPITokenRevoke = "api_token.revoke"
+
+	ActionTaskCreate  = "task.create"
+	ActionTaskUpdate  = "task.update"
+	ActionTaskDelete  = "task.delete"
+	ActionTaskRevert  = "task.revert"
+	ActionTaskRestore = "task.restore"
+
+	ActionCommentCreate = "comment.create"
+	ActionCommentUpdate = "comment.update"
+	ActionCommentDelete = "comment.delete"
+
+	ActionAttachmentUpload = "attachment.upload"
+	ActionAttachmentDelete = "attachment.delete"
+
+	ActionRoleCreate           = "role.create"
+	ActionRoleRename           = "role.rename"
+	ActionRoleDelete           = "role.delete"
+	ActionRolePermissionAttach = "role.permission_attach"
+	ActionRolePermissionDetach = "role.permission_detach"
+
+	ActionUserUpdate = "user.update"
+	ActionUserUnlock = "user.unlock"
+
+	ActionProjectCreate       = "project.create"
+	ActionProjectUpdate       = "project.update"
+	ActionProjectMemberAdd    = "project.member_add"
+	ActionProjectMemberUpdate = "project.member_update"
+	ActionProjectMemberRemove = "project.member_remove"
+)
+
+// Kinds of resource an entry can target.
+const (
+	TargetTask       = "task"
+	TargetComment    = "comment"
+	TargetAttachment = "attachment"
+	TargetRole       = "role"
+	TargetUser       = "user"
+	TargetProject    = "project"
+	TargetSettings   = "settings"
+	TargetAPIToken   = "api_token"
+)
+
+// Event describes an action to record.
+type Event struct {
+	Action     string
+	TargetType string
+	TargetID   primitive.ObjectID
+	// Actor overrides the authenticated principal, for requests such as
+	// login where the caller is identified by the handler itself.
+	Actor *primitive.ObjectID
+	// Before and After are snapshots of the target. Either may be nil, for
+	// example when the target was created or deleted.
+	Before interface{}
+	After  interface{}
+}
+
+// Logger writes audit entries for HTTP requests.
+type Logger struct {
+	entries store.AuditStore
+}
+
+func NewLogger(entries store.AuditStore) *Logger {
+	return &Logger{entries: entries}
+}
+
+// Record appends an entry for the current request. The action has already
+// happened by the time it is recorded, so a failed write is logged rather
+// than reported to the caller.
+func (l *Logger) Record(c *fiber.Ctx, event Event) {
+	entry := models.AuditEntry{
+		ActorID:    event.Actor,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		Changes:    Diff(event.Before, event.After),
+		IP:         c.IP(),
+		CreatedAt:  time.Now(),
+	}
+	if entry.ActorID == nil {
+		if principal := middleware.CurrentPrincipal(c); principal != nil {
+			actorID := principal.User.ID
+			entry.ActorID = &actorID
+		}
+	}
+	if !event.TargetID.IsZero() {
+		targetID := event.TargetID
+		entry.TargetID = &targetID
+	}
+
+	if err := l.entries.Append(c.UserContext(), &entry); err != nil {
+		log.Printf("Failed to write audit entry %s: %v", event.Action, err)
+	}
+}
+
+// Diff compares the JSON representations of before and after and returns
+// the fields whose values differ. A missing field and a null one are treated
+// alike. Fields hidden from JSON, such as password hashes, never appear.
+func Diff(before, after interface{}) map[string]models.FieldChange {
+	beforeFields := jsonFields(before)
+	afterFields := jsonFields(after)
+
+	changes := map[string]models.FieldChange{}
+	for name, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[name]) {
+			changes[name] = models.FieldChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok && value != nil {
+			changes[name] = models.FieldChange{After: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+func jsonFields(v interface{}) map[string]interface{} {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
@@ -0,0 +1,493 @@
+// Package config loads the application settings from an optional YAML
+// file, the .env file and the process environment, in increasing order of
+// precedence, and validates them once at startup.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/workflow"
+
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is read when CONFIG_FILE is not set. It is optional.
+const DefaultFile = "config.yaml"
+
+type Config struct {
+	Server      Server      `yaml:"server"`
+	Database    Database    `yaml:"database"`
+	Auth        Auth        `yaml:"auth"`
+	Tasks       Tasks       `yaml:"tasks"`
+	Attachments Attachments `yaml:"attachments"`
+	Mail        Mail        `yaml:"mail"`
+	Login       Login       `yaml:"login"`
+}
+
+type Server struct {
+	Port string `yaml:"port"`
+	// CORSOrigins lists the frontend origins allowed to make credentialed
+	// requests.
+	CORSOrigins []string `yaml:"cors_origins"`
+	// ShutdownTimeout bounds how long in-flight requests may take to finish
+	// once a termination signal arrives.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// DrainPeriod is how long the readiness probe fails before shutdown
+	// starts, giving load balancers time to stop routing here.
+	DrainPeriod time.Duration `yaml:"drain_period"`
+	// AppURL is the public address of the frontend, used to build the links
+	// sent by email.
+	AppURL string `yaml:"app_url"`
+	// ProxyHeader names the header carrying the client IP, such as
+	// X-Real-IP, when the server runs behind a load balancer. It is only
+	// believed for requests from TrustedProxies; without it every client
+	// appears to have the proxy's IP and shares its login throttling.
+	ProxyHeader string `yaml:"proxy_header"`
+	// TrustedProxies lists the IPs or CIDR ranges of the load balancers
+	// allowed to set ProxyHeader.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+type Database struct {
+	URI  string `yaml:"uri"`
+	Name string `yaml:"name"`
+}
+
+type Auth struct {
+	JWTSecret       string        `yaml:"jwt_secret"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+	BcryptCost      int           `yaml:"bcrypt_cost"`
+	// RequireVerifiedEmail rejects logins until the user has followed the
+	// link sent on registration.
+	RequireVerifiedEmail bool          `yaml:"require_verified_email"`
+	VerificationTokenTTL time.Duration `yaml:"verification_token_ttl"`
+	ResetTokenTTL        time.Duration `yaml:"reset_token_ttl"`
+	// MFAIssuer names the application in authenticator apps.
+	MFAIssuer string `yaml:"mfa_issuer"`
+	// MFAChallengeTTL is how long a user has to enter their code after a
+	// password login on an account with two-factor authentication.
+	MFAChallengeTTL time.Duration `yaml:"mfa_challenge_ttl"`
+}
+
+type Tasks struct {
+	// TrashRetention is how long a deleted task stays restorable before it
+	// is purged.
+	TrashRetention time.Duration `yaml:"trash_retention"`
+	// PurgeInterval is how often the purge job runs.
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+	// Workflow lists the task statuses and the allowed moves between them.
+	Workflow workflow.Workflow `yaml:"workflow"`
+}
+
+type Attachments struct {
+	// Dir is where the local storage backend keeps uploaded files.
+	Dir string `yaml:"dir"`
+	// MaxSize is the largest accepted upload, in bytes.
+	MaxSize int64 `yaml:"max_size"`
+	// AllowedTypes lists the accepted MIME types. The type is detected from
+	// the file content rather than taken from the client.
+	AllowedTypes []string `yaml:"allowed_types"`
+}
+
+// Mail configures outgoing email. When SMTPHost is empty messages are not
+// sent but appended to File, or written to the log when File is empty too.
+type Mail struct {
+	From         string `yaml:"from"`
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+	File         string `yaml:"file"`
+}
+
+// Where login attempt counters are kept.
+const (
+	// LoginStoreMongo shares the counters between every instance.
+	LoginStoreMongo = "mongo"
+	// LoginStoreMemory keeps them in this process, which is enough for a
+	// single instance and forgets them on restart.
+	LoginStoreMemory = "memory"
+)
+
+// Login throttles failed logins per client IP and per email address. Each
+// failure doubles the wait before the next attempt, starting at BackoffBase
+// and capped at BackoffMax, and reaching the failure limit locks the key out
+// for LockoutDuration.
+type Login struct {
+	Store            string        `yaml:"store"`
+	MaxFailures      int           `yaml:"max_failures"`
+	MaxFailuresPerIP int           `yaml:"max_failures_per_ip"`
+	BackoffBase      time.Duration `yaml:"backoff_base"`
+	BackoffMax       time.Duration `yaml:"backoff_max"`
+	LockoutDuration  time.Duration `yaml:"lockout_duration"`
+	// FailureWindow is how long a failure counts; a key with no failures
+	// for this long starts over.
+	FailureWindow time.Duration `yaml:"failure_window"`
+}
+
+// Default returns the settings used when nothing overrides them. The
+// database URI and JWT secret have no default and must be provided.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:            "8000",
+			CORSOrigins:     []string{"http://localhost:5173"},
+			ShutdownTimeout: 10 * time.Second,
+			DrainPeriod:     5 * time.Second,
+			AppURL:          "http://localhost:5173",
+		},
+		Database: Database{
+			Name: "golang_db",
+		},
+		Auth: Auth{
+			AccessTokenTTL:       15 * time.Minute,
+			RefreshTokenTTL:      7 * 24 * time.Hour,
+			BcryptCost:           14,
+			RequireVerifiedEmail: true,
+			VerificationTokenTTL: 48 * time.Hour,
+			ResetTokenTTL:        time.Hour,
+			MFAIssuer:            "Task Manager",
+			MFAChallengeTTL:      5 * time.Minute,
+		},
+		Tasks: Tasks{
+			TrashRetention: 30 * 24 * time.Hour,
+			PurgeInterval:  time.Hour,
+			Workflow:       workflow.Default(),
+		},
+		Attachments: Attachments{
+			Dir:     "uploads",
+			MaxSize: 10 << 20,
+			AllowedTypes: []string{
+				"image/png",
+				"image/jpeg",
+				"image/gif",
+				"image/webp",
+				"application/pdf",
+				"application/zip",
+				"application/x-gzip",
+				"text/plain",
+			},
+		},
+		Mail: Mail{
+			From:     "no-reply@localhost",
+			SMTPPort: 587,
+		},
+		Login: Login{
+			Store:            LoginStoreMongo,
+			MaxFailures:      5,
+			MaxFailuresPerIP: 50,
+			BackoffBase:      time.Second,
+			BackoffMax:       time.Minute,
+			LockoutDuration:  15 * time.Minute,
+			FailureWindow:    time.Hour,
+		},
+	}
+}
+
+// Load builds the configuration and validates it.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("config: loading .env: %w", err)
+	}
+
+	cfg := Default()
+
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit {
+		path = DefaultFile
+	}
+	if err := cfg.loadFile(path, explicit); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// loadFile merges the YAML file at path into cfg. A missing file is only an
+// error when it was asked for explicitly.
+func (cfg *Config) loadFile(path string, required bool) error {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("config: reading %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(raw, cfg); err != nil {
+		return fmt.Errorf("config: parsing %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv overrides cfg with every variable that is set.
+func (cfg *Config) loadEnv() error {
+	setString(&cfg.Server.Port, "PORT")
+	if origins, ok := lookup("CORS_ORIGINS"); ok {
+		cfg.Server.CORSOrigins = splitList(origins)
+	}
+	if err := setDuration(&cfg.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Server.DrainPeriod, "DRAIN_PERIOD"); err != nil {
+		return err
+	}
+	setString(&cfg.Server.AppURL, "APP_URL")
+	setString(&cfg.Server.ProxyHeader, "PROXY_HEADER")
+	if proxies, ok := lookup("TRUSTED_PROXIES"); ok {
+		cfg.Server.TrustedProxies = splitList(proxies)
+	}
+	setString(&cfg.Database.URI, "MONGODB_URI")
+	setString(&cfg.Database.Name, "MONGODB_DATABASE")
+	setString(&cfg.Auth.JWTSecret, "JWT_SECRET_KEY")
+
+	if err := setDuration(&cfg.Auth.AccessTokenTTL, "ACCESS_TOKEN_TTL"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Auth.RefreshTokenTTL, "REFRESH_TOKEN_TTL"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Auth.VerificationTokenTTL, "VERIFICATION_TOKEN_TTL"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Auth.ResetTokenTTL, "RESET_TOKEN_TTL"); err != nil {
+		return err
+	}
+	setString(&cfg.Auth.MFAIssuer, "MFA_ISSUER")
+	if err := setDuration(&cfg.Auth.MFAChallengeTTL, "MFA_CHALLENGE_TTL"); err != nil {
+		return err
+	}
+	if value, ok := lookup("REQUIRE_VERIFIED_EMAIL"); ok {
+		required, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("config: REQUIRE_VERIFIED_EMAIL must be true or false")
+		}
+		cfg.Auth.RequireVerifiedEmail = required
+	}
+	if err := setDuration(&cfg.Tasks.TrashRetention, "TASK_TRASH_RETENTION"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Tasks.PurgeInterval, "TASK_PURGE_INTERVAL"); err != nil {
+		return err
+	}
+	setString(&cfg.Attachments.Dir, "ATTACHMENTS_DIR")
+	if value, ok := lookup("ATTACHMENT_MAX_SIZE"); ok {
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("config: ATTACHMENT_MAX_SIZE must be a number of bytes")
+		}
+		cfg.Attachments.MaxSize = size
+	}
+	if types, ok := lookup("ATTACHMENT_TYPES"); ok {
+		cfg.Attachments.AllowedTypes = splitList(types)
+	}
+	setString(&cfg.Mail.From, "MAIL_FROM")
+	setString(&cfg.Mail.SMTPHost, "SMTP_HOST")
+	if value, ok := lookup("SMTP_PORT"); ok {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("config: SMTP_PORT must be an integer")
+		}
+		cfg.Mail.SMTPPort = port
+	}
+	setString(&cfg.Mail.SMTPUsername, "SMTP_USERNAME")
+	setString(&cfg.Mail.SMTPPassword, "SMTP_PASSWORD")
+	setString(&cfg.Mail.File, "MAIL_FILE")
+	setString(&cfg.Login.Store, "LOGIN_THROTTLE_STORE")
+	if err := setInt(&cfg.Login.MaxFailures, "LOGIN_MAX_FAILURES"); err != nil {
+		return err
+	}
+	if err := setInt(&cfg.Login.MaxFailuresPerIP, "LOGIN_MAX_FAILURES_PER_IP"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Login.BackoffBase, "LOGIN_BACKOFF_BASE"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Login.BackoffMax, "LOGIN_BACKOFF_MAX"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Login.LockoutDuration, "LOGIN_LOCKOUT_DURATION"); err != nil {
+		return err
+	}
+	if err := setDuration(&cfg.Login.FailureWindow, "LOGIN_FAILURE_WINDOW"); err != nil {
+		return err
+	}
+	if value, ok := lookup("BCRYPT_COST"); ok {
+		cost, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("config: BCRYPT_COST must be an integer")
+		}
+		cfg.Auth.BcryptCost = cost
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (cfg *Config) Validate() error {
+	var problems []string
+
+	if port, err := strconv.Atoi(cfg.Server.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, "server port must be a number between 1 and 65535")
+	}
+	if len(cfg.Server.CORSOrigins) == 0 {
+		problems = append(problems, "at least one CORS origin is required")
+	}
+	if cfg.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "shutdown timeout must be positive")
+	}
+	if cfg.Server.DrainPeriod < 0 {
+		problems = append(problems, "drain period must not be negative")
+	}
+	if _, err := url.ParseRequestURI(cfg.Server.AppURL); err != nil {
+		problems = append(problems, "app URL must be an absolute URL")
+	}
+	if cfg.Server.ProxyHeader != "" && len(cfg.Server.TrustedProxies) == 0 {
+		problems = append(problems, "trusted proxies are required when a proxy header is set")
+	}
+	for _, proxy := range cfg.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				problems = append(problems, fmt.Sprintf("trusted proxy %q must be an IP address or CIDR range", proxy))
+			}
+		}
+	}
+	if cfg.Database.URI == "" {
+		problems = append(problems, "MONGODB_URI is required")
+	}
+	if cfg.Database.Name == "" {
+		problems = append(problems, "database name is required")
+	}
+	if cfg.Auth.JWTSecret == "" {
+		problems = append(problems, "JWT_SECRET_KEY is required")
+	}
+	if cfg.Auth.AccessTokenTTL <= 0 {
+		problems = append(problems, "access token TTL must be positive")
+	}
+	if cfg.Auth.RefreshTokenTTL <= cfg.Auth.AccessTokenTTL {
+		problems = append(problems, "refresh token TTL must be longer than the access token TTL")
+	}
+	if cfg.Auth.BcryptCost < bcrypt.MinCost || cfg.Auth.BcryptCost > bcrypt.MaxCost {
+		problems = append(problems, fmt.Sprintf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+	}
+	if cfg.Auth.VerificationTokenTTL <= 0 {
+		problems = append(problems, "verification token TTL must be positive")
+	}
+	if cfg.Auth.ResetTokenTTL <= 0 {
+		problems = append(problems, "reset token TTL must be positive")
+	}
+	if cfg.Auth.MFAIssuer == "" || strings.Contains(cfg.Auth.MFAIssuer, ":") {
+		problems = append(problems, "MFA issuer is required and must not contain a colon")
+	}
+	if cfg.Auth.MFAChallengeTTL <= 0 {
+		problems = append(problems, "MFA challenge TTL must be positive")
+	}
+	if cfg.Tasks.TrashRetention <= 0 {
+		problems = append(problems, "task trash retention must be positive")
+	}
+	if cfg.Tasks.PurgeInterval <= 0 {
+		problems = append(problems, "task purge interval must be positive")
+	}
+	if err := cfg.Tasks.Workflow.Validate(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if cfg.Attachments.Dir == "" {
+		problems = append(problems, "attachments directory is required")
+	}
+	if cfg.Attachments.MaxSize <= 0 {
+		problems = append(problems, "attachment max size must be positive")
+	}
+	if len(cfg.Attachments.AllowedTypes) == 0 {
+		problems = append(problems, "at least one attachment type must be allowed")
+	}
+
+	if _, err := mail.ParseAddress(cfg.Mail.From); err != nil {
+		problems = append(problems, "mail sender must be a valid email address")
+	}
+	if cfg.Mail.SMTPHost != "" && (cfg.Mail.SMTPPort < 1 || cfg.Mail.SMTPPort > 65535) {
+		problems = append(problems, "SMTP port must be a number between 1 and 65535")
+	}
+
+	if cfg.Login.Store != LoginStoreMongo && cfg.Login.Store != LoginStoreMemory {
+		problems = append(problems, fmt.Sprintf("login throttle store must be %q or %q", LoginStoreMongo, LoginStoreMemory))
+	}
+	if cfg.Login.MaxFailures < 1 || cfg.Login.MaxFailuresPerIP < 1 {
+		problems = append(problems, "login failure limits must be at least 1")
+	}
+	if cfg.Login.BackoffBase < 0 || cfg.Login.BackoffMax < cfg.Login.BackoffBase {
+		problems = append(problems, "login backoff must be non-negative and its maximum at least its base")
+	}
+	if cfg.Login.LockoutDuration <= 0 {
+		problems = append(problems, "login lockout duration must be positive")
+	}
+	if cfg.Login.FailureWindow <= 0 {
+		problems = append(problems, "login failure window must be positive")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("config: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// lookup returns the trimmed value of a set, non-blank variable.
+func lookup(name string) (string, bool) {
+	value := strings.TrimSpace(os.Getenv(name))
+	return value, value != ""
+}
+
+func setString(target *string, name string) {
+	if value, ok := lookup(name); ok {
+		*target = value
+	}
+}
+
+func setDuration(target *time.Duration, name string) error {
+	value, ok := lookup(name)
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("config: %s must be a duration such as 15m or 168h", name)
+	}
+	*target = d
+	return nil
+}
+
+func setInt(target *int, name string) error {
+	value, ok := lookup(name)
+	if !ok {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("config: %s must be an integer", name)
+	}
+	*target = n
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/audit"
+	"backend/internal/mail"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+	"backend/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mailTimeout bounds how long a background email delivery may take.
+const mailTimeout = 30 * time.Second
+
+// Responses to requests that must not reveal whether an account exists.
+const (
+	resendAccepted = "If the account exists and is not verified, a verification email has been sent"
+	resetAccepted  = "If the account exists, a password reset email has been sent"
+)
+
+type tokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type emailRequest struct {
+	Email string `json:"email" validate:"required,email,max=254"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// VerifyEmail marks the owner of a verification token as verified.
+func (h *AuthController) VerifyEmail(c *fiber.Ctx) error {
+	var data tokenRequest
+	if err := c.BodyParser(&data); err != nil {
+		return validation.InvalidBody(c)
+	}
+	if errs := validation.Struct(data); len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+
+	ctx := c.UserContext()
+	token, err := h.stores.Tokens.UseAccountToken(ctx, middleware.HashToken(data.Token), models.TokenPurposeVerifyEmail, time.Now())
+	if errors.Is(err, store.ErrNotFound) {
+		return invalidAccountToken(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify token"})
+	}
+
+	verified := true
+	user, err := h.stores.Users.Update(ctx, token.UserID, store.UserChanges{EmailVerified: &verified})
+	if errors.Is(err, store.ErrNotFound) {
+		return invalidAccountToken(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify email"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionEmailVerified, TargetType: audit.TargetUser, TargetID: user.ID, Actor: &user.ID})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Email verified successfully"})
+}
+
+// ResendVerification sends a new verification email to an unverified
+// account, replacing any earlier link. The response is the same whether or
+// not the account exists.
+func (h *AuthController) ResendVerification(c *fiber.Ctx) error {
+	email, ok, err := parseEmailRequest(c)
+	if !ok {
+		return err
+	}
+
+	user, err := h.stores.Users.FindByEmail(c.UserContext(), email)
+	if err == nil && !user.EmailVerified && !user.Disabled {
+		if err := h.sendVerification(c.UserContext(), *user); err != nil {
+			log.Printf("Failed to send verification email to user %s: %v", user.ID.Hex(), err)
+		}
+	} else if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve user"})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": resendAccepted})
+}
+
+// ForgotPassword emails a password reset link. The response is the same
+// whether or not the account exists.
+func (h *AuthController) ForgotPassword(c *fiber.Ctx) error {
+	email, ok, err := parseEmailRequest(c)
+	if !ok {
+		return err
+	}
+
+	ctx := c.UserContext()
+	user, err := h.stores.Users.FindByEmail(ctx, email)
+	if err == nil && !user.Disabled {
+		var token string
+		token, err = h.createAccountToken(ctx, *user, models.TokenPurposeResetPassword, h.cfg.ResetTokenTTL)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create reset token"})
+		}
+		h.sendMail(*user, mail.Message{
+			To:      user.Email,
+			Subject: "Reset your password",
+			Body: "Hi " + user.Name + ",\n\n" +
+				"Someone asked to reset the password of your account. Follow this link to choose a new one:\n\n" +
+				h.link("/reset-password", token) + "\n\n" +
+				"The link expires in " + h.cfg.ResetTokenTTL.String() + ". If you did not ask for a reset, you can ignore this email.\n",
+		})
+	} else if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve user"})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": resetAccepted})
+}
+
+// ResetPassword sets a new password using a reset token and ends every
+// existing session of the account: refresh tokens, access tokens issued so
+// far, pending two-factor challenges and personal API tokens. It also lifts
+// a login lockout. Receiving the link proves ownership of the address, so
+// the email is marked verified as well.
+func (h *AuthController) ResetPassword(c *fiber.Ctx) error {
+	var data resetPasswordRequest
+	if err := c.BodyParser(&data); err != nil {
+		return validation.InvalidBody(c)
+	}
+	if errs := validation.Struct(data); len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+
+	ctx := c.UserContext()
+	token, err := h.stores.Tokens.UseAccountToken(ctx, middleware.HashToken(data.Token), models.TokenPurposeResetPassword, time.Now())
+	if errors.Is(err, store.ErrNotFound) {
+		return invalidAccountToken(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify token"})
+	}
+
+	password, err := bcrypt.GenerateFromPassword([]byte(data.Password), h.cfg.BcryptCost)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to hash password"})
+	}
+	// Access tokens carry their issue time in whole seconds. Rounding the
+	// reset down keeps every session started after it valid, at the cost of
+	// also accepting tokens issued earlier within the same second.
+	verified := true
+	validAfter := time.Now().Truncate(time.Second)
+	user, err := h.stores.Users.Update(ctx, token.UserID, store.UserChanges{
+		Password:           password,
+		EmailVerified:      &verified,
+		SessionsValidAfter: &validAfter,
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		return invalidAccountToken(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to reset password"})
+	}
+	if err := h.stores.Tokens.RevokeUserRefreshTokens(ctx, user.ID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke sessions"})
+	}
+	if err := h.stores.Tokens.DeleteAccountTokens(ctx, user.ID, models.TokenPurposeMFAChallenge); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke sessions"})
+	}
+	if err := h.stores.APITokens.DeleteByUser(ctx, user.ID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke API tokens"})
+	}
+	// Failed logins from before the reset must not keep the owner out.
+	if err := h.limiter.Reset(ctx, user.Email); err != nil {
+		log.Printf("Failed to reset login attempts for user %s: %v", user.ID.Hex(), err)
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionPasswordReset, TargetType: audit.TargetUser, TargetID: user.ID, Actor: &user.ID})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Password reset successfully"})
+}
+
+// sendVerification replaces a user's verification token and emails the
+// new link.
+func (h *AuthController) sendVerification(ctx context.Context, user models.User) error {
+	token, err := h.createAccountToken(ctx, user, models.TokenPurposeVerifyEmail, h.cfg.VerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+	h.sendMail(user, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: "Hi " + user.Name + ",\n\n" +
+			"Follow this link to verify your email address:\n\n" +
+			h.link("/verify-email", token) + "\n\n" +
+			"The link expires in " + h.cfg.VerificationTokenTTL.String() + ".\n",
+	})
+	return nil
+}
+
+// createAccountToken replaces the user's outstanding tokens for purpose
+// with a new one and returns its plaintext value. Only the hash is stored.
+func (h *AuthController) createAccountToken(ctx context.Context, user models.User, purpose string, ttl time.Duration) (string, error) {
+	if err := h.stores.Tokens.DeleteAccountTokens(ctx, user.ID, purpose); err != nil {
+		return "", err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	err = h.stores.Tokens.CreateAccountToken(ctx, &models.AccountToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: middleware.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// sendMail delivers msg in the background so that responses neither wait
+// for the mail server nor reveal through their timing whether an account
+// exists. Failures are only logged.
+func (h *AuthController) sendMail(user models.User, msg mail.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+		if err := h.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send %q email to user %s: %v", msg.Subject, user.ID.Hex(), err)
+		}
+	}()
+}
+
+// link builds a frontend URL carrying token as a query parameter.
+func (h *AuthController) link(path, token string) string {
+	return strings.TrimRight(h.appURL, "/") + path + "?token=" + token
+}
+
+// parseEmailRequest reads the email address from a request body. When ok
+// is false the error response has already been written and err is its
+// result.
+func parseEmailRequest(c *fiber.Ctx) (email string, ok bool, err error) {
+	var data emailRequest
+	if err := c.BodyParser(&data); err != nil {
+		return "", false, validation.InvalidBody(c)
+	}
+	data.Email = strings.TrimSpace(data.Email)
+	if errs := validation.Struct(data); len(errs) > 0 {
+		return "", false, validation.Respond(c, errs)
+	}
+	return data.Email, true, nil
+}
+
+func invalidAccountToken(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "Invalid or expired token",
+		"code":  "invalid_token",
+	})
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/audit"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+	"backend/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultAPITokenDays = 30
+	maxAPITokenDays     = 365
+	maxAPITokensPerUser = 50
+	// apiTokenHintLength is how much of the token, after the prefix, is
+	// kept for display.
+	apiTokenHintLength = 4
+)
+
+// APITokenController lets users manage personal API tokens for scripts and
+// CI integrations.
+type APITokenController struct {
+	stores *store.Stores
+	audit  *audit.Logger
+}
+
+func NewAPITokenController(stores *store.Stores, auditLog *audit.Logger) *APITokenController {
+	return &APITokenController{stores: stores, audit: auditLog}
+}
+
+type apiTokenRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,max=50"`
+	// ExpiresInDays defaults to defaultAPITokenDays.
+	ExpiresInDays *int `json:"expires_in_days"`
+}
+
+func (h *APITokenController) ListTokens(c *fiber.Ctx) error {
+	user := middleware.CurrentPrincipal(c).User
+	tokens, err := h.stores.APITokens.ListByUser(c.UserContext(), user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve API tokens"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"tokens": tokens})
+}
+
+// CreateToken mints a token limited to the requested scopes, each of which
+// must be a permission the user currently holds. The token itself is only
+// ever returned in this response.
+func (h *APITokenController) CreateToken(c *fiber.Ctx) error {
+	var body apiTokenRequest
+	if err := c.BodyParser(&body); err != nil {
+		return validation.InvalidBody(c)
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	errs := validation.Struct(body)
+
+	days := defaultAPITokenDays
+	if body.ExpiresInDays != nil {
+		days = *body.ExpiresInDays
+		if days < 1 || days > maxAPITokenDays {
+			errs.Add("expires_in_days", validation.CodeInvalidValue, "must be between 1 and 365")
+		}
+	}
+
+	ctx := c.UserContext()
+	user := middleware.CurrentPrincipal(c).User
+	held, err := heldPermissions(ctx, h.stores, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve permissions"})
+	}
+	seen := make(map[string]bool, len(body.Scopes))
+	scopes := make([]string, 0, len(body.Scopes))
+	for _, scope := range body.Scopes {
+		scope = strings.TrimSpace(scope)
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		if !held[scope] {
+			errs.Add("scopes", validation.CodeInvalidValue, "you do not hold the permission "+scope)
+			continue
+		}
+		scopes = append(scopes, scope)
+	}
+	if len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+	sort.Strings(scopes)
+
+	existing, err := h.stores.APITokens.ListByUser(ctx, user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve API tokens"})
+	}
+	if len(existing) >= maxAPITokensPerUser {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Too many API tokens; revoke one first"})
+	}
+
+	secret, err := generateToken()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate API token"})
+	}
+	plaintext := models.APITokenPrefix + secret
+	now := time.Now()
+	token := models.APIToken{
+		UserID:    user.ID,
+		Name:      body.Name,
+		TokenHash: middleware.HashToken(plaintext),
+		Hint:      plaintext[:len(models.APITokenPrefix)+apiTokenHintLength],
+		Scopes:    scopes,
+		ExpiresAt: now.AddDate(0, 0, days),
+		CreatedAt: now,
+	}
+	if err := h.stores.APITokens.Create(ctx, &token); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create API token"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionAPITokenCreate, TargetType: audit.TargetAPIToken, TargetID: token.ID, After: token})
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":   "API token created. Copy it now; it will not be shown again.",
+		"token":     plaintext,
+		"api_token": token,
+	})
+}
+
+func (h *APITokenController) RevokeToken(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid API token ID"})
+	}
+
+	user := middleware.CurrentPrincipal(c).User
+	err = h.stores.APITokens.Delete(c.UserContext(), user.ID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "API token not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke API token"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionAPITokenRevoke, TargetType: audit.TargetAPIToken, TargetID: id})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "API token revoked"})
+}
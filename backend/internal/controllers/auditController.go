@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"strings"
+	"time"
+
+	"backend/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+// AuditController lets administrators query the audit log.
+type AuditController struct {
+	stores *store.Stores
+}
+
+func NewAuditController(stores *store.Stores) *AuditController {
+	return &AuditController{stores: stores}
+}
+
+// ListAuditEntries returns one page of audit entries, newest first. Entries
+// can be filtered by actor, action, target and an RFC 3339 time range.
+func (h *AuditController) ListAuditEntries(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit := c.QueryInt("limit", defaultAuditPageSize)
+	if limit < 1 || limit > maxAuditPageSize {
+		limit = defaultAuditPageSize
+	}
+
+	filter := store.AuditFilter{Action: strings.TrimSpace(c.Query("action"))}
+	for param, target := range map[string]**primitive.ObjectID{
+		"actor":  &filter.ActorID,
+		"target": &filter.TargetID,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": param + " must be a valid ID"})
+		}
+		*target = &id
+	}
+	for param, target := range map[string]**time.Time{
+		"from": &filter.From,
+		"to":   &filter.To,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": param + " must be an RFC 3339 timestamp"})
+		}
+		*target = &t
+	}
+
+	entries, total, err := h.stores.Audit.List(c.UserContext(), filter, (page-1)*limit, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve audit log"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"entries": entries,
+		"page":    page,
+		"limit":   limit,
+		"total":   total,
+	})
+}
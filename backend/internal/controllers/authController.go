@@ -1,217 +1,285 @@
 package controllers
 
 import (
-	"context"
+	"backend/internal/audit"
+	"backend/internal/config"
+	"backend/internal/mail"
+	"backend/internal/middleware"
 	"backend/internal/models"
-	"backend/internal/database"
+	"backend/internal/store"
+	"backend/internal/throttle"
+	"backend/internal/validation"
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
-	"go.mongodb.org/mongo-driver/bson"
-	"github.com/golang-jwt/jwt/v5"
-	"os"
+	"log"
+	"math"
+	"strconv"
+	"strings"
 	"time"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// AuthController handles registration, login, session management and the
+// email verification and password reset flows.
+type AuthController struct {
+	stores *store.Stores
+	auth   *middleware.Auth
+	cfg    config.Auth
+	audit  *audit.Logger
+	mailer mail.Mailer
+	// appURL is the base URL of the frontend, used to build the links in
+	// account emails.
+	appURL  string
+	limiter *throttle.LoginLimiter
+}
+
+func NewAuthController(stores *store.Stores, auth *middleware.Auth, cfg config.Auth, auditLog *audit.Logger, mailer mail.Mailer, appURL string, limiter *throttle.LoginLimiter) *AuthController {
+	return &AuthController{stores: stores, auth: auth, cfg: cfg, audit: auditLog, mailer: mailer, appURL: appURL, limiter: limiter}
+}
+
+type registerRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Email    string `json:"email" validate:"required,email,max=254"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+type loginRequest struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (h *AuthController) Register(c *fiber.Ctx) error {
+	var data registerRequest
 
-func Register(c *fiber.Ctx) error {
-	var data map[string]string
-	
 	if err := c.BodyParser(&data); err != nil {
-		return err
+		return validation.InvalidBody(c)
 	}
-	
-	password, _ := bcrypt.GenerateFromPassword([]byte(data["password"]), 14)
-	user := models.User{
-		Name:     data["name"],
-		Email:    data["email"],
-		Password: password,
+	data.Name = strings.TrimSpace(data.Name)
+	data.Email = strings.TrimSpace(data.Email)
+	if errs := validation.Struct(data); len(errs) > 0 {
+		return validation.Respond(c, errs)
 	}
 
+	ctx := c.UserContext()
+	_, err := h.stores.Users.FindByEmail(ctx, data.Email)
+	if err == nil {
+		return emailTaken(c)
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check email",
+		})
+	}
 
-	var role models.Role
-	collection := database.GetCollection("roles")
+	password, err := bcrypt.GenerateFromPassword([]byte(data.Password), h.cfg.BcryptCost)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to hash password",
+		})
+	}
+	user := models.User{
+		Name:     data.Name,
+		Email:    data.Email,
+		Password: password,
+	}
 
-	userCollection := database.GetCollection("users")
-	count, _ := userCollection.CountDocuments(context.Background(), bson.M{})
+	// The first registered user becomes the administrator.
+	roleName := models.RoleUser
+	count, err := h.stores.Users.Count(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count users",
+		})
+	}
 	if count == 0 {
+		roleName = models.RoleAdmin
+	}
 
-		err := collection.FindOne(context.Background(), bson.M{"name": "admin"}).Decode(&role)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Error fetching admin role",
-			})
-		}
-	} else {
-		err := collection.FindOne(context.Background(), bson.M{"name": "user"}).Decode(&role)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Error fetching user role",
-			})
-		}
+	role, err := h.stores.Roles.FindByName(ctx, roleName)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error fetching " + roleName + " role",
+		})
 	}
 
 	user.RoleID = role.ID
 
-
-	collection = database.GetCollection("users")
-	insertResult, err := collection.InsertOne(context.Background(), user)
+	err = h.stores.Users.Create(ctx, &user)
+	if errors.Is(err, store.ErrDuplicate) {
+		return emailTaken(c)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create user",
 		})
 	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionRegister, TargetType: audit.TargetUser, TargetID: user.ID, Actor: &user.ID, After: user})
+
+	// The account exists either way; the user can ask for a new link.
+	if err := h.sendVerification(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID.Hex(), err)
+	}
 
-	user.ID = insertResult.InsertedID.(primitive.ObjectID)
 	return c.Status(fiber.StatusCreated).JSON(user)
 }
 
-func Login(c *fiber.Ctx) error {
-    var data map[string]string
-
-    if err := c.BodyParser(&data); err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Invalid request body",
-        })
-    }
-
-    email := data["email"]
-    password := data["password"]
-    if email == "" || password == "" {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "error": "Email and password are required",
-        })
-    }
-
-    collection := database.GetCollection("users")
-    var user models.User
-    err := collection.FindOne(context.Background(), bson.M{"email": email}).Decode(&user)
-    if err != nil {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "Invalid email or password",
-        })
-    }
-
-    err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
-    if err != nil {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "Invalid email or password",
-        })
-    }
-
-    expirationTime := time.Now().Add(1 * time.Hour)
-    claims := &models.CustomClaims{
-        Role:   user.RoleID.Hex(),
-        RegisteredClaims: jwt.RegisteredClaims{
-            Issuer:    user.ID.Hex(),
-            ExpiresAt: jwt.NewNumericDate(expirationTime),
-            IssuedAt:  jwt.NewNumericDate(time.Now()),
-            ID:        generateJTI(),
-        },
-    }
-
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-    secretKey := os.Getenv("JWT_SECRET_KEY")
-    if secretKey == "" {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "JWT Secret key is not set",
-        })
-    }
-
-    signedToken, err := token.SignedString([]byte(secretKey))
-    if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "Failed to sign the token",
-        })
-    }
-
-    cookie := fiber.Cookie{
-        Name:     "jwt",
-        Value:    signedToken,
-        Expires:  expirationTime,
-        HTTPOnly: true,
-        Secure:   false,
-    }
-
-    c.Cookie(&cookie)
-    return c.Status(fiber.StatusOK).JSON(fiber.Map{
-        "message": "Login successful",
-        "token":   signedToken,
-    })
+func emailTaken(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"error": "An account with this email already exists",
+		"code":  "email_taken",
+	})
+}
+
+func (h *AuthController) Login(c *fiber.Ctx) error {
+	var data loginRequest
+
+	if err := c.BodyParser(&data); err != nil {
+		return validation.InvalidBody(c)
+	}
+	if errs := validation.Struct(data); len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+
+	email := strings.TrimSpace(data.Email)
+	password := data.Password
+
+	ctx := c.UserContext()
+	wait, err := h.limiter.Wait(ctx, c.IP(), email, time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check login attempts",
+		})
+	}
+	if wait > 0 {
+		return tooManyAttempts(c, wait)
+	}
+
+	user, err := h.stores.Users.FindByEmail(ctx, email)
+	if err != nil {
+		h.loginFailed(c, email, nil)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid email or password",
+		})
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if err != nil {
+		h.audit.Record(c, audit.Event{Action: audit.ActionLoginFailed, TargetType: audit.TargetUser, TargetID: user.ID})
+		h.loginFailed(c, email, user)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid email or password",
+		})
+	}
+
+	if user.Disabled {
+		h.audit.Record(c, audit.Event{Action: audit.ActionLoginFailed, TargetType: audit.TargetUser, TargetID: user.ID})
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Account is disabled",
+		})
+	}
+
+	if h.cfg.RequireVerifiedEmail && !user.EmailVerified {
+		h.audit.Record(c, audit.Event{Action: audit.ActionLoginFailed, TargetType: audit.TargetUser, TargetID: user.ID})
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Email address has not been verified",
+			"code":  "email_not_verified",
+		})
+	}
+
+	mfa, err := h.stores.MFA.Find(ctx, user.ID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check two-factor authentication",
+		})
+	}
+	if err == nil && mfa.Enabled {
+		// Failures are only forgotten once the second factor is verified
+		// too, so that a known password does not reset the count of wrong
+		// codes.
+		return h.startMFAChallenge(c, *user)
+	}
+
+	return h.completeLogin(c, *user)
+}
+
+// completeLogin ends a successful login by clearing the failures recorded
+// for the account and issuing a session.
+func (h *AuthController) completeLogin(c *fiber.Ctx, user models.User) error {
+	if err := h.limiter.Reset(c.UserContext(), user.Email); err != nil {
+		log.Printf("Failed to reset login attempts for user %s: %v", user.ID.Hex(), err)
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionLogin, TargetType: audit.TargetUser, TargetID: user.ID, Actor: &user.ID})
+	return h.issueSession(c, user, primitive.NewObjectID(), fiber.Map{"message": "Login successful"})
+}
+
+// loginFailed counts a failed login towards the throttling limits. Unknown
+// emails count too, so that responses do not reveal which accounts exist.
+func (h *AuthController) loginFailed(c *fiber.Ctx, email string, user *models.User) {
+	locked, err := h.limiter.Fail(c.UserContext(), c.IP(), email, time.Now())
+	if err != nil {
+		log.Printf("Failed to record login attempt: %v", err)
+		return
+	}
+	if locked && user != nil {
+		h.audit.Record(c, audit.Event{Action: audit.ActionAccountLocked, TargetType: audit.TargetUser, TargetID: user.ID})
+	}
+}
+
+func tooManyAttempts(c *fiber.Ctx, wait time.Duration) error {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error":       "Too many failed login attempts, try again later",
+		"code":        "login_throttled",
+		"retry_after": seconds,
+	})
 }
 
 func generateJTI() string {
-    return primitive.NewObjectID().Hex()
+	return primitive.NewObjectID().Hex()
 }
 
-func User(c *fiber.Ctx) error {
-    cookie := c.Cookies("jwt")
-    if cookie == "" {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "Missing token",
-        })
-    }
-
-    secretKey := os.Getenv("JWT_SECRET_KEY")
-    if secretKey == "" {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": "JWT secret key is not set",
-        })
-    }
-
-    
-    token, err := jwt.ParseWithClaims(cookie, &models.CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid signing method")
-        }
-        return []byte(secretKey), nil
-    })
-
-    if err != nil {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-            "error": "Invalid or expired token",
-        })
-    }
-
-    
-    if claims, ok := token.Claims.(*models.CustomClaims); ok && token.Valid {    
-        userID, err := primitive.ObjectIDFromHex(claims.Issuer)
-        if err != nil {
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "error": "Invalid user ID in token",
-            })
-        }
-
-        collection := database.GetCollection("users")
-        var user models.User
-        err = collection.FindOne(context.Background(), bson.M{"_id": userID}).Decode(&user)
-        if err != nil {
-            return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-                "error": "User not found",
-            })
-        }
-
-        
-        return c.JSON(user)
-    }
-
-    return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-        "error": "Invalid token",
-    })
+func (h *AuthController) User(c *fiber.Ctx) error {
+	return c.JSON(middleware.CurrentPrincipal(c).User)
 }
 
-func Logout(c *fiber.Ctx) error {
-    cookie := fiber.Cookie{
-        Name: "jwt",
-        Value: "",
-        Expires: time.Now().Add(-time.Hour),
-        HTTPOnly: true,
-    }
-    c.Cookie(&cookie)
-    return c.JSON(fiber.Map{
-        "message": "success",
-    })
+// Logout revokes the caller's access token and refresh token family and
+// clears the session cookies. When either token comes from a cookie, the
+// request must carry the CSRF token.
+func (h *AuthController) Logout(c *fiber.Ctx) error {
+	accessToken, accessSource := middleware.ExtractToken(c)
+	refreshToken, refreshSource := refreshTokenFromRequest(c)
+	if (accessSource == middleware.TokenCookie || refreshSource == middleware.TokenCookie) && !middleware.ValidCSRF(c) {
+		return middleware.CSRFFailed(c)
+	}
+
+	ctx := c.UserContext()
+	var actor *primitive.ObjectID
+	if claims, err := h.auth.ParseJWT(ctx, accessToken); err == nil {
+		if err := h.revokeAccessToken(ctx, claims); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to revoke token",
+			})
+		}
+		if userID, err := primitive.ObjectIDFromHex(claims.Issuer); err == nil {
+			actor = &userID
+		}
+	}
 
+	if refreshToken != "" {
+		if err := h.revokeRefreshFamily(ctx, refreshToken); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to revoke refresh token",
+			})
+		}
+	}
 
-}
\ No newline at end of file
+	clearSessionCookies(c)
+	if actor != nil {
+		h.audit.Record(c, audit.Event{Action: audit.ActionLogout, TargetType: audit.TargetUser, TargetID: *actor, Actor: actor})
+	}
+	return c.JSON(fiber.Map{
+		"message": "success",
+	})
+}
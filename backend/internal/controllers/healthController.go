@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// HealthController answers liveness and readiness probes.
+type HealthController struct {
+	ping     func(ctx context.Context) error
+	draining atomic.Bool
+}
+
+// NewHealthController returns a controller whose readiness probe succeeds
+// only while ping does.
+func NewHealthController(ping func(ctx context.Context) error) *HealthController {
+	return &HealthController{ping: ping}
+}
+
+// Live reports that the process is up and serving requests.
+func (h *HealthController) Live(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ok"})
+}
+
+// Drain makes the readiness probe fail from now on, so that load balancers
+// stop routing new requests here before the server shuts down.
+func (h *HealthController) Drain() {
+	h.draining.Store(true)
+}
+
+// Ready reports whether the server accepts new work: it is not draining and
+// the database can be reached.
+func (h *HealthController) Ready(c *fiber.Ctx) error {
+	if h.draining.Load() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"status": "draining",
+			"error":  "Server is shutting down",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+	defer cancel()
+
+	if err := h.ping(ctx); err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"status": "unavailable",
+			"error":  "Database is unreachable",
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ready"})
+}
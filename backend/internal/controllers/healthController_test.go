@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func readyStatus(t *testing.T, h *HealthController) int {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/readyz", h.Ready)
+	resp, err := app.Test(httptest.NewRequest("GET", "/readyz", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestReadyFailsWhileDraining(t *testing.T) {
+	h := NewHealthController(func(context.Context) error { return nil })
+	if status := readyStatus(t, h); status != http.StatusOK {
+		t.Fatalf("ready before draining = %d, want 200", status)
+	}
+
+	h.Drain()
+	if status := readyStatus(t, h); status != http.StatusServiceUnavailable {
+		t.Errorf("ready while draining = %d, want 503", status)
+	}
+}
+
+func TestReadyFailsWithoutDatabase(t *testing.T) {
+	h := NewHealthController(func(context.Context) error { return errors.New("unreachable") })
+	if status := readyStatus(t, h); status != http.StatusServiceUnavailable {
+		t.Errorf("ready without database = %d, want 503", status)
+	}
+}
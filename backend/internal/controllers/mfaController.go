@@ -0,0 +1,499 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"backend/internal/audit"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+	"backend/internal/totp"
+	"backend/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recoveryCodeCount is how many recovery codes a user gets at a time.
+const recoveryCodeCount = 10
+
+type mfaCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type mfaLoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+// GetMFA reports the caller's two-factor authentication status and whether
+// the MFA policy requires it of them.
+func (h *AuthController) GetMFA(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := middleware.CurrentPrincipal(c).User
+
+	required, err := mfaPolicyApplies(ctx, h.stores, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve MFA policy"})
+	}
+
+	status := fiber.Map{"enabled": false, "pending": false, "required": required}
+	mfa, err := h.stores.MFA.Find(ctx, user.ID)
+	if err == nil {
+		status["enabled"] = mfa.Enabled
+		status["pending"] = !mfa.Enabled
+		if mfa.Enabled {
+			status["recovery_codes_remaining"] = len(mfa.RecoveryCodes)
+		}
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve two-factor authentication"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(status)
+}
+
+// EnrollMFA starts setting up two-factor authentication. It returns a new
+// secret and its otpauth URI for the authenticator app; nothing changes at
+// login until a code is confirmed with EnableMFA.
+func (h *AuthController) EnrollMFA(c *fiber.Ctx) error {
+	user := middleware.CurrentPrincipal(c).User
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate secret"})
+	}
+	err = h.stores.MFA.SavePending(c.UserContext(), &models.MFA{
+		UserID:    user.ID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	})
+	if errors.Is(err, store.ErrDuplicate) {
+		return mfaAlreadyEnabled(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to start two-factor enrollment"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"secret":      secret,
+		"otpauth_uri": totp.URI(h.cfg.MFAIssuer, user.Email, secret),
+	})
+}
+
+// EnableMFA confirms a pending enrollment with a code from the
+// authenticator app. The response carries the recovery codes, which are
+// shown only this once, and a new session that is no longer limited to
+// enrollment.
+func (h *AuthController) EnableMFA(c *fiber.Ctx) error {
+	code, ok, err := parseMFACode(c)
+	if !ok {
+		return err
+	}
+
+	ctx := c.UserContext()
+	user := middleware.CurrentPrincipal(c).User
+	mfa, err := h.stores.MFA.Find(ctx, user.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Two-factor enrollment has not been started"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve two-factor authentication"})
+	}
+	if mfa.Enabled {
+		return mfaAlreadyEnabled(c)
+	}
+
+	valid, err := h.useTOTP(ctx, mfa, code)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify code"})
+	}
+	if !valid {
+		return invalidMFACode(c)
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate recovery codes"})
+	}
+	err = h.stores.MFA.Enable(ctx, user.ID, hashes, time.Now())
+	if errors.Is(err, store.ErrNotFound) {
+		return mfaAlreadyEnabled(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to enable two-factor authentication"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionMFAEnable, TargetType: audit.TargetUser, TargetID: user.ID})
+
+	return h.issueSession(c, user, primitive.NewObjectID(), fiber.Map{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes. It needs a
+// current code, so that a stolen session alone cannot mint new ones.
+func (h *AuthController) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	code, ok, err := parseMFACode(c)
+	if !ok {
+		return err
+	}
+
+	ctx := c.UserContext()
+	user := middleware.CurrentPrincipal(c).User
+	mfa, ok, err := h.verifyEnabledMFA(c, user, code)
+	if !ok {
+		return err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate recovery codes"})
+	}
+	if err := h.stores.MFA.SetRecoveryCodes(ctx, mfa.UserID, hashes); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to store recovery codes"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionMFARecoveryRegenerate, TargetType: audit.TargetUser, TargetID: user.ID})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Recovery codes regenerated", "recovery_codes": codes})
+}
+
+// DisableMFA turns two-factor authentication off, given a current code or
+// a recovery code. Users the MFA policy covers cannot turn it off. A
+// pending enrollment is simply discarded.
+func (h *AuthController) DisableMFA(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	user := middleware.CurrentPrincipal(c).User
+
+	mfa, err := h.stores.MFA.Find(ctx, user.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Two-factor authentication is not enabled"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve two-factor authentication"})
+	}
+
+	if mfa.Enabled {
+		code, ok, err := parseMFACode(c)
+		if !ok {
+			return err
+		}
+		required, err := mfaPolicyApplies(ctx, h.stores, user)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve MFA policy"})
+		}
+		if required {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Two-factor authentication is required for your role"})
+		}
+		if _, ok, err := h.verifyEnabledMFA(c, user, code); !ok {
+			return err
+		}
+	}
+
+	err = h.stores.MFA.Delete(ctx, user.ID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to disable two-factor authentication"})
+	}
+	if mfa.Enabled {
+		h.audit.Record(c, audit.Event{Action: audit.ActionMFADisable, TargetType: audit.TargetUser, TargetID: user.ID})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Two-factor authentication disabled"})
+}
+
+// LoginMFA completes a login on an account with two-factor authentication,
+// exchanging the challenge token from Login and a current or recovery code
+// for a session. Wrong codes count towards the login throttling limits.
+func (h *AuthController) LoginMFA(c *fiber.Ctx) error {
+	var data mfaLoginRequest
+	if err := c.BodyParser(&data); err != nil {
+		return validation.InvalidBody(c)
+	}
+	if errs := validation.Struct(data); len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+
+	ctx := c.UserContext()
+	tokenHash := middleware.HashToken(data.MFAToken)
+	challenge, err := h.stores.Tokens.FindAccountToken(ctx, tokenHash, models.TokenPurposeMFAChallenge, time.Now())
+	if errors.Is(err, store.ErrNotFound) {
+		return invalidMFAChallenge(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify challenge"})
+	}
+
+	user, err := h.stores.Users.FindByID(ctx, challenge.UserID)
+	if errors.Is(err, store.ErrNotFound) {
+		return invalidMFAChallenge(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve user"})
+	}
+	if user.Disabled {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Account is disabled"})
+	}
+
+	wait, err := h.limiter.Wait(ctx, c.IP(), user.Email, time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check login attempts"})
+	}
+	if wait > 0 {
+		return tooManyAttempts(c, wait)
+	}
+
+	mfa, err := h.stores.MFA.Find(ctx, user.ID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve two-factor authentication"})
+	}
+	if err != nil || !mfa.Enabled {
+		// Two-factor authentication was turned off after the challenge was
+		// issued; the password has to be checked again.
+		return invalidMFAChallenge(c)
+	}
+
+	valid, recovery, err := h.useMFACode(ctx, mfa, data.Code)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify code"})
+	}
+	if !valid {
+		h.audit.Record(c, audit.Event{Action: audit.ActionLoginFailed, TargetType: audit.TargetUser, TargetID: user.ID})
+		h.loginFailed(c, user.Email, user)
+		return invalidMFACode(c)
+	}
+
+	_, err = h.stores.Tokens.UseAccountToken(ctx, tokenHash, models.TokenPurposeMFAChallenge, time.Now())
+	if errors.Is(err, store.ErrNotFound) {
+		return invalidMFAChallenge(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify challenge"})
+	}
+	if recovery {
+		h.audit.Record(c, audit.Event{Action: audit.ActionMFARecoveryUsed, TargetType: audit.TargetUser, TargetID: user.ID, Actor: &user.ID})
+	}
+
+	return h.completeLogin(c, *user)
+}
+
+// startMFAChallenge answers a correct password on an account with
+// two-factor authentication with a short-lived challenge token instead of
+// a session.
+func (h *AuthController) startMFAChallenge(c *fiber.Ctx, user models.User) error {
+	token, err := h.createAccountToken(c.UserContext(), user, models.TokenPurposeMFAChallenge, h.cfg.MFAChallengeTTL)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create two-factor challenge"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":      "Two-factor authentication required",
+		"mfa_required": true,
+		"mfa_token":    token,
+		"expires_at":   time.Now().Add(h.cfg.MFAChallengeTTL),
+	})
+}
+
+// verifyEnabledMFA checks a current or recovery code against the caller's
+// enabled enrollment. Wrong codes count towards the login throttling limits
+// like they do at login. When ok is false the error response has already
+// been written and err is its result.
+func (h *AuthController) verifyEnabledMFA(c *fiber.Ctx, user models.User, code string) (mfa *models.MFA, ok bool, err error) {
+	ctx := c.UserContext()
+	wait, waitErr := h.limiter.Wait(ctx, c.IP(), user.Email, time.Now())
+	if waitErr != nil {
+		return nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check login attempts"})
+	}
+	if wait > 0 {
+		return nil, false, tooManyAttempts(c, wait)
+	}
+
+	mfa, findErr := h.stores.MFA.Find(ctx, user.ID)
+	if errors.Is(findErr, store.ErrNotFound) || (findErr == nil && !mfa.Enabled) {
+		return nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Two-factor authentication is not enabled"})
+	}
+	if findErr != nil {
+		return nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve two-factor authentication"})
+	}
+
+	valid, recovery, useErr := h.useMFACode(ctx, mfa, code)
+	if useErr != nil {
+		return nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify code"})
+	}
+	if !valid {
+		h.loginFailed(c, user.Email, &user)
+		return nil, false, invalidMFACode(c)
+	}
+	if recovery {
+		h.audit.Record(c, audit.Event{Action: audit.ActionMFARecoveryUsed, TargetType: audit.TargetUser, TargetID: user.ID})
+	}
+	return mfa, true, nil
+}
+
+// useMFACode accepts either a current TOTP code or one of the unused
+// recovery codes, using it up. recovery reports which kind matched.
+func (h *AuthController) useMFACode(ctx context.Context, mfa *models.MFA, code string) (valid, recovery bool, err error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		valid, err = h.useTOTP(ctx, mfa, code)
+		return valid, false, err
+	}
+
+	err = h.stores.MFA.UseRecoveryCode(ctx, mfa.UserID, middleware.HashToken(normalizeRecoveryCode(code)))
+	if errors.Is(err, store.ErrNotFound) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, true, nil
+}
+
+// useTOTP validates a TOTP code and records its time step, so that the same
+// code is rejected if it is presented again.
+func (h *AuthController) useTOTP(ctx context.Context, mfa *models.MFA, code string) (bool, error) {
+	step, ok := totp.Validate(mfa.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return false, nil
+	}
+	err := h.stores.MFA.UseStep(ctx, mfa.UserID, step)
+	if errors.Is(err, store.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// needsMFAEnrollment reports whether the MFA policy covers user and they
+// have not enabled two-factor authentication yet.
+func (h *AuthController) needsMFAEnrollment(ctx context.Context, user models.User) (bool, error) {
+	mfa, err := h.stores.MFA.Find(ctx, user.ID)
+	if err == nil && mfa.Enabled {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return false, err
+	}
+	return mfaPolicyApplies(ctx, h.stores, user)
+}
+
+// mfaPolicy returns the saved MFA policy, or the default one when none has
+// been saved.
+func mfaPolicy(ctx context.Context, stores *store.Stores) (*models.MFAPolicy, error) {
+	policy, err := stores.MFA.FindPolicy(ctx)
+	if errors.Is(err, store.ErrNotFound) {
+		defaults := models.DefaultMFAPolicy()
+		return &defaults, nil
+	}
+	return policy, err
+}
+
+// mfaPolicyApplies reports whether the MFA policy is enforced and user holds
+// one of its permissions, through their global role or any project role.
+func mfaPolicyApplies(ctx context.Context, stores *store.Stores, user models.User) (bool, error) {
+	policy, err := mfaPolicy(ctx, stores)
+	if err != nil || !policy.Enforced || len(policy.Permissions) == 0 {
+		return false, err
+	}
+
+	held, err := heldPermissions(ctx, stores, user)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range policy.Permissions {
+		if held[name] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// heldPermissions returns the names of the permissions granted to user by
+// the global role or by the role in any project they belong to.
+func heldPermissions(ctx context.Context, stores *store.Stores, user models.User) (map[string]bool, error) {
+	members, err := stores.Members.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	roleIDs := map[primitive.ObjectID]bool{user.RoleID: true}
+	for _, member := range members {
+		roleIDs[member.RoleID] = true
+	}
+
+	var permissionIDs []primitive.ObjectID
+	for roleID := range roleIDs {
+		role, err := stores.Roles.FindByID(ctx, roleID)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		permissionIDs = append(permissionIDs, role.Permissions...)
+	}
+	permissions, err := stores.Permissions.FindByIDs(ctx, permissionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		names[permission.Name] = true
+	}
+	return names, nil
+}
+
+// generateRecoveryCodes returns new recovery codes, formatted for display,
+// together with the hashes to store.
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(buf))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, middleware.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode ignores case, spaces and dashes, however the user
+// copied the code.
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
+
+// parseMFACode reads the code from a request body. When ok is false the
+// error response has already been written and err is its result.
+func parseMFACode(c *fiber.Ctx) (code string, ok bool, err error) {
+	var data mfaCodeRequest
+	if err := c.BodyParser(&data); err != nil {
+		return "", false, validation.InvalidBody(c)
+	}
+	if errs := validation.Struct(data); len(errs) > 0 {
+		return "", false, validation.Respond(c, errs)
+	}
+	return data.Code, true, nil
+}
+
+func mfaAlreadyEnabled(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Two-factor authentication is already enabled"})
+}
+
+func invalidMFACode(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"error": "Invalid two-factor code",
+		"code":  "invalid_mfa_code",
+	})
+}
+
+func invalidMFAChallenge(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"error": "Invalid or expired two-factor challenge, log in again",
+		"code":  "invalid_mfa_challenge",
+	})
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/store"
+	"backend/internal/totp"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUseTOTPRejectsReplay(t *testing.T) {
+	ctx := context.Background()
+	stores := store.NewMemoryStores()
+	h := &AuthController{stores: stores}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfa := &models.MFA{UserID: primitive.NewObjectID(), Secret: secret, CreatedAt: time.Now()}
+	if err := stores.MFA.SavePending(ctx, mfa); err != nil {
+		t.Fatal(err)
+	}
+	if err := stores.MFA.Enable(ctx, mfa.UserID, nil, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := totp.Code(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := h.useTOTP(ctx, mfa, code); err != nil || !ok {
+		t.Fatalf("first use = %v, %v; want accepted", ok, err)
+	}
+	if ok, err := h.useTOTP(ctx, mfa, code); err != nil || ok {
+		t.Fatalf("replay = %v, %v; want rejected", ok, err)
+	}
+
+	// A code from the previous step is within the skew window but older
+	// than the one already used, so it is rejected too.
+	earlier, err := totp.Code(secret, time.Now().Add(-totp.Period))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := h.useTOTP(ctx, mfa, earlier); err != nil || ok {
+		t.Fatalf("earlier code = %v, %v; want rejected", ok, err)
+	}
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/audit"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+	"backend/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MFAPolicyController lets administrators decide who must use two-factor
+// authentication.
+type MFAPolicyController struct {
+	stores *store.Stores
+	audit  *audit.Logger
+}
+
+func NewMFAPolicyController(stores *store.Stores, auditLog *audit.Logger) *MFAPolicyController {
+	return &MFAPolicyController{stores: stores, audit: auditLog}
+}
+
+type mfaPolicyRequest struct {
+	Enforced    bool     `json:"enforced"`
+	Permissions []string `json:"permissions"`
+}
+
+func (h *MFAPolicyController) GetPolicy(c *fiber.Ctx) error {
+	policy, err := mfaPolicy(c.UserContext(), h.stores)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve MFA policy"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"policy": policy})
+}
+
+// UpdatePolicy replaces the MFA policy. Users it newly covers get sessions
+// limited to setting up two-factor authentication from their next login or
+// token refresh.
+func (h *MFAPolicyController) UpdatePolicy(c *fiber.Ctx) error {
+	var body mfaPolicyRequest
+	if err := c.BodyParser(&body); err != nil {
+		return validation.InvalidBody(c)
+	}
+
+	ctx := c.UserContext()
+	seen := make(map[string]bool, len(body.Permissions))
+	permissions := make([]string, 0, len(body.Permissions))
+	var errs validation.Errors
+	for _, name := range body.Permissions {
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		_, err := h.stores.Permissions.FindByName(ctx, name)
+		if errors.Is(err, store.ErrNotFound) {
+			errs.Add("permissions", validation.CodeInvalidValue, "unknown permission "+name)
+			continue
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve permissions"})
+		}
+		permissions = append(permissions, name)
+	}
+	if body.Enforced && len(permissions) == 0 {
+		errs.Add("permissions", validation.CodeRequired, "must list at least one permission when enforced")
+	}
+	if len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+	sort.Strings(permissions)
+
+	before, err := mfaPolicy(ctx, h.stores)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve MFA policy"})
+	}
+	actor := middleware.CurrentPrincipal(c).User.ID
+	now := time.Now()
+	policy := models.MFAPolicy{
+		Enforced:    body.Enforced,
+		Permissions: permissions,
+		UpdatedAt:   &now,
+		UpdatedBy:   &actor,
+	}
+	if err := h.stores.MFA.SavePolicy(ctx, &policy); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update MFA policy"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionMFAPolicyUpdate, TargetType: audit.TargetSettings, Before: before, After: policy})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "MFA policy updated successfully", "policy": policy})
+}
@@ -0,0 +1,344 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/audit"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+	"backend/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ownerRoleName is the role given to the creator of a project.
+const ownerRoleName = models.RoleAdmin
+
+// ProjectController manages projects and their members.
+type ProjectController struct {
+	stores *store.Stores
+	audit  *audit.Logger
+}
+
+func NewProjectController(stores *store.Stores, auditLog *audit.Logger) *ProjectController {
+	return &ProjectController{stores: stores, audit: auditLog}
+}
+
+// ListProjects returns the projects the caller is a member of, or every
+// project for holders of administer_projects.
+func (h *ProjectController) ListProjects(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	principal := middleware.CurrentPrincipal(c)
+
+	var projects []models.Project
+	var err error
+	if principal.Can(middleware.AdministerProjects) {
+		projects, err = h.stores.Projects.List(ctx)
+	} else {
+		var members []models.ProjectMember
+		members, err = h.stores.Members.ListByUser(ctx, principal.User.ID)
+		if err == nil {
+			ids := make([]primitive.ObjectID, 0, len(members))
+			for _, member := range members {
+				ids = append(ids, member.ProjectID)
+			}
+			projects, err = h.stores.Projects.FindByIDs(ctx, ids)
+		}
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve projects"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"projects": projects})
+}
+
+// CreateProject creates a project and makes the caller its first member,
+// with the admin role.
+func (h *ProjectController) CreateProject(c *fiber.Ctx) error {
+	var project models.Project
+	if err := c.BodyParser(&project); err != nil {
+		return validation.InvalidBody(c)
+	}
+	project.Name = strings.TrimSpace(project.Name)
+	if errs := validation.Struct(project); len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+
+	ctx := c.UserContext()
+	role, err := h.stores.Roles.FindByName(ctx, ownerRoleName)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve role"})
+	}
+
+	userID := middleware.CurrentPrincipal(c).User.ID
+	project.ID = primitive.NilObjectID
+	project.CreatedBy = userID
+	project.CreatedAt = time.Now()
+	if err := h.stores.Projects.Create(ctx, &project); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create project"})
+	}
+
+	member := models.ProjectMember{ProjectID: project.ID, UserID: userID, RoleID: role.ID, CreatedAt: project.CreatedAt}
+	if err := h.stores.Members.Add(ctx, &member); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to add project member"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionProjectCreate, TargetType: audit.TargetProject, TargetID: project.ID, After: project})
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Project created successfully", "project": project})
+}
+
+// GetProject returns the project together with the caller's membership and
+// the permissions it grants.
+func (h *ProjectController) GetProject(c *fiber.Ctx) error {
+	access := middleware.CurrentProject(c)
+
+	permissions := make([]string, 0, len(access.Permissions))
+	for name := range access.Permissions {
+		permissions = append(permissions, name)
+	}
+	sort.Strings(permissions)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"project":     access.Project,
+		"member":      access.Member,
+		"permissions": permissions,
+	})
+}
+
+type projectUpdateRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// UpdateProject renames a project and/or changes its description. Only the
+// fields present in the body are changed.
+func (h *ProjectController) UpdateProject(c *fiber.Ctx) error {
+	var body projectUpdateRequest
+	if err := c.BodyParser(&body); err != nil {
+		return validation.InvalidBody(c)
+	}
+	if body.Name == nil && body.Description == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Nothing to update"})
+	}
+
+	before := middleware.CurrentProject(c).Project
+	updated := before
+	if body.Name != nil {
+		name := strings.TrimSpace(*body.Name)
+		body.Name = &name
+		updated.Name = name
+	}
+	if body.Description != nil {
+		updated.Description = *body.Description
+	}
+	if errs := validation.Struct(updated); len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+
+	project, err := h.stores.Projects.Update(c.UserContext(), before.ID, store.ProjectChanges{Name: body.Name, Description: body.Description})
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Project not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update project"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionProjectUpdate, TargetType: audit.TargetProject, TargetID: project.ID, Before: before, After: project})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Project updated successfully", "project": project})
+}
+
+func (h *ProjectController) ListMembers(c *fiber.Ctx) error {
+	members, err := h.stores.Members.ListByProject(c.UserContext(), middleware.CurrentProject(c).Project.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve project members"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"members": members})
+}
+
+type memberRequest struct {
+	UserID string `json:"user_id"`
+	RoleID string `json:"role_id"`
+}
+
+// AddMember grants an existing user a role in the project.
+func (h *ProjectController) AddMember(c *fiber.Ctx) error {
+	var body memberRequest
+	if err := c.BodyParser(&body); err != nil {
+		return validation.InvalidBody(c)
+	}
+
+	userID, err := primitive.ObjectIDFromHex(body.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	ctx := c.UserContext()
+	roleID, ok, err := h.parseRole(c, body.RoleID)
+	if !ok {
+		return err
+	}
+
+	_, err = h.stores.Users.FindByID(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve user"})
+	}
+
+	member := models.ProjectMember{
+		ProjectID: middleware.CurrentProject(c).Project.ID,
+		UserID:    userID,
+		RoleID:    roleID,
+		CreatedAt: time.Now(),
+	}
+	err = h.stores.Members.Add(ctx, &member)
+	if errors.Is(err, store.ErrDuplicate) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "User is already a member of this project"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to add project member"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionProjectMemberAdd, TargetType: audit.TargetProject, TargetID: member.ProjectID, After: member})
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Member added successfully", "member": member})
+}
+
+// UpdateMember changes the role a member holds in the project.
+func (h *ProjectController) UpdateMember(c *fiber.Ctx) error {
+	userID, err := primitive.ObjectIDFromHex(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	var body memberRequest
+	if err := c.BodyParser(&body); err != nil {
+		return validation.InvalidBody(c)
+	}
+	roleID, ok, err := h.parseRole(c, body.RoleID)
+	if !ok {
+		return err
+	}
+
+	ctx := c.UserContext()
+	projectID := middleware.CurrentProject(c).Project.ID
+	before, err := h.stores.Members.Find(ctx, projectID, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Member not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve project member"})
+	}
+	last, err := h.lastManager(ctx, *before, &roleID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check permissions"})
+	}
+	if last {
+		return lastManagerConflict(c)
+	}
+
+	member, err := h.stores.Members.UpdateRole(ctx, projectID, userID, roleID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Member not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update project member"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionProjectMemberUpdate, TargetType: audit.TargetProject, TargetID: projectID, Before: before, After: member})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Member updated successfully", "member": member})
+}
+
+// RemoveMember revokes a user's access to the project. Tasks they created
+// or are assigned to are left untouched.
+func (h *ProjectController) RemoveMember(c *fiber.Ctx) error {
+	userID, err := primitive.ObjectIDFromHex(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	ctx := c.UserContext()
+	projectID := middleware.CurrentProject(c).Project.ID
+	before, err := h.stores.Members.Find(ctx, projectID, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Member not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve project member"})
+	}
+	last, err := h.lastManager(ctx, *before, nil)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check permissions"})
+	}
+	if last {
+		return lastManagerConflict(c)
+	}
+
+	err = h.stores.Members.Remove(ctx, projectID, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Member not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to remove project member"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionProjectMemberRemove, TargetType: audit.TargetProject, TargetID: projectID, Before: before})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Member removed successfully"})
+}
+
+// lastManager reports whether member is the project's last member holding
+// manage_project and would lose it by moving to newRole, or by leaving the
+// project when newRole is nil. Without such a member only holders of
+// administer_projects could manage the project.
+func (h *ProjectController) lastManager(ctx context.Context, member models.ProjectMember, newRole *primitive.ObjectID) (bool, error) {
+	permission, err := h.stores.Permissions.FindByName(ctx, "manage_project")
+	if errors.Is(err, store.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	holders, err := rolesGranting(ctx, h.stores, permission.ID)
+	if err != nil {
+		return false, err
+	}
+	if !slices.Contains(holders, member.RoleID) || (newRole != nil && slices.Contains(holders, *newRole)) {
+		return false, nil
+	}
+	// The count includes member, who currently holds it.
+	count, err := h.stores.Members.CountByRoles(ctx, member.ProjectID, holders)
+	if err != nil {
+		return false, err
+	}
+	return count <= 1, nil
+}
+
+func lastManagerConflict(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "This is the last member holding manage_project; grant it to another member first"})
+}
+
+// parseRole resolves a role ID from a request body. When ok is false the
+// error response has already been written and err is its result.
+func (h *ProjectController) parseRole(c *fiber.Ctx, id string) (roleID primitive.ObjectID, ok bool, err error) {
+	roleID, parseErr := primitive.ObjectIDFromHex(id)
+	if parseErr != nil {
+		return roleID, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid role ID"})
+	}
+
+	_, findErr := h.stores.Roles.FindByID(c.UserContext(), roleID)
+	if errors.Is(findErr, store.ErrNotFound) {
+		return roleID, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Role not found"})
+	}
+	if findErr != nil {
+		return roleID, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve role"})
+	}
+	return roleID, true, nil
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/audit"
+	"backend/internal/config"
+	"backend/internal/initialize"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testJWTSecret = "test-secret"
+
+// memberTest serves the member endpoints for a project whose only member,
+// manager, holds manage_project through the admin role.
+type memberTest struct {
+	t         *testing.T
+	app       *fiber.App
+	stores    *store.Stores
+	project   models.Project
+	manager   models.User
+	adminRole *models.Role
+	userRole  *models.Role
+}
+
+func newMemberTest(t *testing.T) *memberTest {
+	t.Helper()
+	ctx := context.Background()
+	stores := store.NewMemoryStores()
+	initialize.InitializePermissionsAndRoles(stores)
+
+	m := &memberTest{t: t, stores: stores}
+	var err error
+	if m.adminRole, err = stores.Roles.FindByName(ctx, models.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if m.userRole, err = stores.Roles.FindByName(ctx, models.RoleUser); err != nil {
+		t.Fatal(err)
+	}
+	m.project = models.Project{Name: "Launch", CreatedAt: time.Now()}
+	if err := stores.Projects.Create(ctx, &m.project); err != nil {
+		t.Fatal(err)
+	}
+	m.manager = m.addMember("manager@example.com", m.adminRole.ID)
+
+	auth := middleware.NewAuth(stores, config.Auth{JWTSecret: testJWTSecret})
+	h := NewProjectController(stores, audit.NewLogger(stores.Audit))
+	m.app = fiber.New()
+	m.app.Patch("/api/projects/:pid/members/:userId", auth.RequireProjectPermission("manage_project"), h.UpdateMember)
+	m.app.Delete("/api/projects/:pid/members/:userId", auth.RequireProjectPermission("manage_project"), h.RemoveMember)
+	return m
+}
+
+// addMember creates a user whose global role grants nothing in projects and
+// makes them a member of the project with roleID.
+func (m *memberTest) addMember(email string, roleID primitive.ObjectID) models.User {
+	m.t.Helper()
+	ctx := context.Background()
+	user := models.User{Name: email, Email: email, RoleID: m.userRole.ID}
+	if err := m.stores.Users.Create(ctx, &user); err != nil {
+		m.t.Fatal(err)
+	}
+	member := models.ProjectMember{ProjectID: m.project.ID, UserID: user.ID, RoleID: roleID, CreatedAt: time.Now()}
+	if err := m.stores.Members.Add(ctx, &member); err != nil {
+		m.t.Fatal(err)
+	}
+	return user
+}
+
+// do sends a request on behalf of the manager and returns the status code.
+func (m *memberTest) do(method string, target models.User, body string) int {
+	m.t.Helper()
+	now := time.Now()
+	claims := &models.CustomClaims{RegisteredClaims: jwt.RegisteredClaims{
+		Issuer:    m.manager.ID.Hex(),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		ID:        primitive.NewObjectID().Hex(),
+	}}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		m.t.Fatal(err)
+	}
+
+	path := "/api/projects/" + m.project.ID.Hex() + "/members/" + target.ID.Hex()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := m.app.Test(req, -1)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestUpdateMemberKeepsLastManager(t *testing.T) {
+	m := newMemberTest(t)
+	demote := `{"role_id":"` + m.userRole.ID.Hex() + `"}`
+
+	if status := m.do("PATCH", m.manager, demote); status != http.StatusConflict {
+		t.Fatalf("demoting the last manager = %d, want 409", status)
+	}
+
+	member := m.addMember("member@example.com", m.userRole.ID)
+	if status := m.do("PATCH", member, `{"role_id":"`+m.adminRole.ID.Hex()+`"}`); status != http.StatusOK {
+		t.Fatalf("promoting a member = %d, want 200", status)
+	}
+	if status := m.do("PATCH", m.manager, demote); status != http.StatusOK {
+		t.Errorf("demoting a manager who is not the last = %d, want 200", status)
+	}
+}
+
+func TestRemoveMemberKeepsLastManager(t *testing.T) {
+	m := newMemberTest(t)
+
+	if status := m.do("DELETE", m.manager, ""); status != http.StatusConflict {
+		t.Fatalf("removing the last manager = %d, want 409", status)
+	}
+
+	member := m.addMember("member@example.com", m.userRole.ID)
+	if status := m.do("DELETE", member, ""); status != http.StatusOK {
+		t.Fatalf("removing a member without manage_project = %d, want 200", status)
+	}
+
+	m.addMember("second@example.com", m.adminRole.ID)
+	if status := m.do("DELETE", m.manager, ""); status != http.StatusOK {
+		t.Errorf("removing a manager who is not the last = %d, want 200", status)
+	}
+}
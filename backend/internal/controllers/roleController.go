@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"backend/internal/audit"
+	"backend/internal/models"
+	"backend/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// lockoutPermissions must stay granted to at least one enabled user's
+// global role. Losing the last holder of either would leave nobody able to
+// administer roles or users again.
+var lockoutPermissions = []string{"manage_roles", "manage_users"}
+
+// RoleController manages roles and the permissions attached to them.
+type RoleController struct {
+	stores *store.Stores
+	audit  *audit.Logger
+}
+
+func NewRoleController(stores *store.Stores, auditLog *audit.Logger) *RoleController {
+	return &RoleController{stores: stores, audit: auditLog}
+}
+
+func (h *RoleController) GetPermissions(c *fiber.Ctx) error {
+	permissions, err := h.stores.Permissions.List(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve permissions"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"permissions": permissions})
+}
+
+func (h *RoleController) GetRoles(c *fiber.Ctx) error {
+	roles, err := h.stores.Roles.List(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve roles"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"roles": roles})
+}
+
+func (h *RoleController) CreateRole(c *fiber.Ctx) error {
+	var data map[string]string
+	if err := c.BodyParser(&data); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	name := strings.TrimSpace(data["name"])
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Role name is required"})
+	}
+
+	role := models.Role{Name: name, Permissions: []primitive.ObjectID{}}
+	err := h.stores.Roles.Create(c.UserContext(), &role)
+	if errors.Is(err, store.ErrDuplicate) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "A role with this name already exists"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create role"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionRoleCreate, TargetType: audit.TargetRole, TargetID: role.ID, After: role})
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Role created successfully", "role": role})
+}
+
+func (h *RoleController) RenameRole(c *fiber.Ctx) error {
+	roleID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid role ID"})
+	}
+
+	var data map[string]string
+	if err := c.BodyParser(&data); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	name := strings.TrimSpace(data["name"])
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Role name is required"})
+	}
+
+	ctx := c.UserContext()
+	var role *models.Role
+	before, err := h.stores.Roles.FindByID(ctx, roleID)
+	if err == nil && before.BuiltIn() && before.Name != name {
+		return builtInRole(c)
+	}
+	if err == nil {
+		role, err = h.stores.Roles.Rename(ctx, roleID, name)
+	}
+	if errors.Is(err, store.ErrDuplicate) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "A role with this name already exists"})
+	}
+	return h.respondRole(c, audit.ActionRoleRename, before, role, err, "Role renamed successfully")
+}
+
+func (h *RoleController) DeleteRole(c *fiber.Ctx) error {
+	roleID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid role ID"})
+	}
+
+	ctx := c.UserContext()
+	role, err := h.stores.Roles.FindByID(ctx, roleID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Role not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve role"})
+	}
+	if role.BuiltIn() {
+		return builtInRole(c)
+	}
+
+	count, err := h.stores.Users.CountByRole(ctx, roleID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check role usage"})
+	}
+	members, err := h.stores.Members.CountByRole(ctx, roleID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check role usage"})
+	}
+	if count > 0 || members > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":           "Role is still assigned to users",
+			"users":           count,
+			"project_members": members,
+		})
+	}
+
+	err = h.stores.Roles.Delete(ctx, roleID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Role not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete role"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionRoleDelete, TargetType: audit.TargetRole, TargetID: roleID, Before: role})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Role deleted successfully"})
+}
+
+func (h *RoleController) AttachPermission(c *fiber.Ctx) error {
+	roleID, permissionID, err := rolePermissionParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx := c.UserContext()
+	_, err = h.stores.Permissions.FindByID(ctx, permissionID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Permission not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve permission"})
+	}
+
+	var role *models.Role
+	before, err := h.stores.Roles.FindByID(ctx, roleID)
+	if err == nil {
+		role, err = h.stores.Roles.AddPermissions(ctx, roleID, permissionID)
+	}
+	return h.respondRole(c, audit.ActionRolePermissionAttach, before, role, err, "Permission attached successfully")
+}
+
+func (h *RoleController) DetachPermission(c *fiber.Ctx) error {
+	roleID, permissionID, err := rolePermissionParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx := c.UserContext()
+	var role *models.Role
+	before, err := h.stores.Roles.FindByID(ctx, roleID)
+	if err == nil && slices.Contains(before.Permissions, permissionID) {
+		var last string
+		last, err = h.lastHolderOf(ctx, before.ID, permissionID)
+		if err == nil && last != "" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "No other role assigned to an enabled user holds " + last + "; grant it to one first",
+			})
+		}
+	}
+	if err == nil {
+		role, err = h.stores.Roles.RemovePermission(ctx, roleID, permissionID)
+	}
+	return h.respondRole(c, audit.ActionRolePermissionDetach, before, role, err, "Permission detached successfully")
+}
+
+// lastHolderOf returns the name of the permission when it is one of the
+// lockoutPermissions and no role other than roleID that is assigned to an
+// enabled user holds it. It returns "" otherwise.
+func (h *RoleController) lastHolderOf(ctx context.Context, roleID, permissionID primitive.ObjectID) (string, error) {
+	permission, err := h.stores.Permissions.FindByID(ctx, permissionID)
+	if errors.Is(err, store.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(lockoutPermissions, permission.Name) {
+		return "", nil
+	}
+
+	others, err := rolesGranting(ctx, h.stores, permissionID)
+	if err != nil {
+		return "", err
+	}
+	others = slices.DeleteFunc(others, func(id primitive.ObjectID) bool { return id == roleID })
+	count, err := h.stores.Users.CountEnabledByRoles(ctx, others)
+	if err != nil || count > 0 {
+		return "", err
+	}
+	return permission.Name, nil
+}
+
+// rolesGranting returns the IDs of the roles that hold the permission.
+func rolesGranting(ctx context.Context, stores *store.Stores, permissionID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	roles, err := stores.Roles.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []primitive.ObjectID
+	for _, role := range roles {
+		if slices.Contains(role.Permissions, permissionID) {
+			ids = append(ids, role.ID)
+		}
+	}
+	return ids, nil
+}
+
+func builtInRole(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Built-in roles cannot be renamed or deleted"})
+}
+
+func rolePermissionParams(c *fiber.Ctx) (primitive.ObjectID, primitive.ObjectID, error) {
+	roleID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, fiber.NewError(fiber.StatusBadRequest, "Invalid role ID")
+	}
+	permissionID, err := primitive.ObjectIDFromHex(c.Params("permissionId"))
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, fiber.NewError(fiber.StatusBadRequest, "Invalid permission ID")
+	}
+	return roleID, permissionID, nil
+}
+
+// respondRole renders the outcome of a role update and records it in the
+// audit log when it succeeded.
+func (h *RoleController) respondRole(c *fiber.Ctx, action string, before, role *models.Role, err error, message string) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Role not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update role"})
+	}
+	h.audit.Record(c, audit.Event{Action: action, TargetType: audit.TargetRole, TargetID: role.ID, Before: before, After: role})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": message, "role": role})
+}
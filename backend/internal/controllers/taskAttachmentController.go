@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/audit"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/storage"
+	"backend/internal/store"
+	"backend/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// attachmentField is the multipart form field carrying the uploaded file.
+const attachmentField = "file"
+
+const maxFilenameLength = 255
+
+// sniffLength is the number of leading bytes http.DetectContentType reads.
+const sniffLength = 512
+
+// cleanFilename keeps only the base name of an uploaded file, whichever
+// path separator the client used.
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	for utf8.RuneCountInString(name) > maxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+// allowedType reports whether contentType, without parameters, is one of
+// the configured attachment types.
+func (h *TaskController) allowedType(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType, false
+	}
+	for _, allowed := range h.attachments.AllowedTypes {
+		if strings.EqualFold(allowed, mediaType) {
+			return mediaType, true
+		}
+	}
+	return mediaType, false
+}
+
+// GetAttachments lists the files attached to a task, oldest first.
+func (h *TaskController) GetAttachments(c *fiber.Ctx) error {
+	taskID, ok, err := h.visibleTaskID(c)
+	if !ok {
+		return err
+	}
+
+	attachments, err := h.stores.Attachments.List(c.UserContext(), taskID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve attachments"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"attachments": attachments})
+}
+
+// UploadAttachment stores the file sent in the "file" field of a multipart
+// form. Its type is detected from the content and must be one of the
+// configured attachment types.
+func (h *TaskController) UploadAttachment(c *fiber.Ctx) error {
+	taskID, ok, err := h.visibleTaskID(c)
+	if !ok {
+		return err
+	}
+
+	header, err := c.FormFile(attachmentField)
+	if err != nil {
+		var errs validation.Errors
+		errs.Add(attachmentField, validation.CodeRequired, "is required")
+		return validation.Respond(c, errs)
+	}
+	if header.Size == 0 {
+		var errs validation.Errors
+		errs.Add(attachmentField, validation.CodeInvalidValue, "must not be empty")
+		return validation.Respond(c, errs)
+	}
+	if header.Size > h.attachments.MaxSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": fmt.Sprintf("Attachments must be at most %d bytes", h.attachments.MaxSize)})
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read attachment"})
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read attachment"})
+	}
+	contentType, ok := h.allowedType(http.DetectContentType(head[:n]))
+	if !ok {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": "Files of type " + contentType + " cannot be attached"})
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read attachment"})
+	}
+
+	ctx := c.UserContext()
+	attachment := models.Attachment{
+		TaskID:      taskID,
+		Filename:    cleanFilename(header.Filename),
+		ContentType: contentType,
+		Size:        header.Size,
+		StorageKey:  primitive.NewObjectID().Hex(),
+		UploadedBy:  middleware.CurrentPrincipal(c).User.ID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.files.Put(ctx, attachment.StorageKey, file); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to store attachment"})
+	}
+	if err := h.stores.Attachments.Create(ctx, &attachment); err != nil {
+		if err := h.files.Delete(ctx, attachment.StorageKey); err != nil {
+			log.Printf("Failed to clean up attachment content %s: %v", attachment.StorageKey, err)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to store attachment"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionAttachmentUpload, TargetType: audit.TargetAttachment, TargetID: attachment.ID, After: attachment})
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Attachment uploaded successfully", "attachment": attachment})
+}
+
+// DownloadAttachment streams the content of an attachment. It is always
+// served as a download so that uploaded HTML or images are never rendered
+// inline by the browser.
+func (h *TaskController) DownloadAttachment(c *fiber.Ctx) error {
+	attachment, ok, err := h.findAttachment(c)
+	if !ok {
+		return err
+	}
+
+	content, err := h.files.Open(c.UserContext(), attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Attachment content not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read attachment"})
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	c.Set(fiber.HeaderContentType, attachment.ContentType)
+	c.Set(fiber.HeaderContentDisposition, disposition)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	return c.Status(fiber.StatusOK).SendStream(content, int(attachment.Size))
+}
+
+// DeleteAttachment removes an attachment and its content.
+func (h *TaskController) DeleteAttachment(c *fiber.Ctx) error {
+	attachment, ok, err := h.findAttachment(c)
+	if !ok {
+		return err
+	}
+
+	ctx := c.UserContext()
+	err = h.stores.Attachments.Delete(ctx, attachment.TaskID, attachment.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Attachment not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete attachment"})
+	}
+	// The metadata is gone, so the attachment is no longer reachable; a
+	// leftover file is only logged.
+	if err := h.files.Delete(ctx, attachment.StorageKey); err != nil {
+		log.Printf("Failed to delete attachment content %s: %v", attachment.StorageKey, err)
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionAttachmentDelete, TargetType: audit.TargetAttachment, TargetID: attachment.ID, Before: attachment})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Attachment deleted successfully"})
+}
+
+// findAttachment resolves the attachment in the path on a task visible to
+// the caller. When ok is false the error response has already been written
+// and err is its result.
+func (h *TaskController) findAttachment(c *fiber.Ctx) (attachment *models.Attachment, ok bool, err error) {
+	taskID, ok, err := h.visibleTaskID(c)
+	if !ok {
+		return nil, false, err
+	}
+
+	attachmentID, parseErr := primitive.ObjectIDFromHex(c.Params("attachmentId"))
+	if parseErr != nil {
+		return nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid attachment ID"})
+	}
+
+	attachment, findErr := h.stores.Attachments.FindByID(c.UserContext(), taskID, attachmentID)
+	if errors.Is(findErr, store.ErrNotFound) {
+		return nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Attachment not found"})
+	}
+	if findErr != nil {
+		return nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve attachment"})
+	}
+	return attachment, true, nil
+}
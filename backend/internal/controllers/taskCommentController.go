@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/audit"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+	"backend/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type commentRequest struct {
+	Body string `json:"body"`
+}
+
+// Kinds of entry in a task's activity feed.
+const (
+	activityComment = "comment"
+	activityChange  = "change"
+)
+
+// activityEntry is one item of a task's activity feed: either a comment or
+// a recorded version of the task together with the fields it changed.
+type activityEntry struct {
+	Type      string                        `json:"type"`
+	ActorID   primitive.ObjectID            `json:"actor_id"`
+	CreatedAt time.Time                     `json:"created_at"`
+	Comment   *models.Comment               `json:"comment,omitempty"`
+	Change    string                        `json:"change,omitempty"`
+	Version   int                           `json:"version,omitempty"`
+	Fields    map[string]models.FieldChange `json:"fields,omitempty"`
+}
+
+// Task fields that change on every version and would only add noise to the
+// activity feed.
+var activityIgnoredFields = []string{"updated_at", "version"}
+
+// visibleTaskID parses the task ID in the path and checks that the task is
+// visible to the caller. When ok is false the error response has already
+// been written and err is its result.
+func (h *TaskController) visibleTaskID(c *fiber.Ctx) (taskID primitive.ObjectID, ok bool, err error) {
+	taskID, parseErr := primitive.ObjectIDFromHex(c.Params("id"))
+	if parseErr != nil {
+		return taskID, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
+	}
+
+	_, findErr := h.stores.Tasks.FindByID(c.UserContext(), taskID, taskScope(c))
+	if errors.Is(findErr, store.ErrNotFound) {
+		return taskID, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+	}
+	if findErr != nil {
+		return taskID, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve task"})
+	}
+	return taskID, true, nil
+}
+
+// parseComment reads and validates a comment body.
+func parseComment(c *fiber.Ctx) (string, validation.Errors, error) {
+	var body commentRequest
+	if err := c.BodyParser(&body); err != nil {
+		return "", nil, err
+	}
+
+	comment := models.Comment{Body: strings.TrimSpace(body.Body)}
+	return comment.Body, validation.Struct(comment), nil
+}
+
+// GetComments lists the comments on a task, oldest first.
+func (h *TaskController) GetComments(c *fiber.Ctx) error {
+	taskID, ok, err := h.visibleTaskID(c)
+	if !ok {
+		return err
+	}
+
+	comments, err := h.stores.Comments.List(c.UserContext(), taskID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve comments"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"comments": comments})
+}
+
+// CreateComment posts a comment on a task as the caller.
+func (h *TaskController) CreateComment(c *fiber.Ctx) error {
+	taskID, ok, err := h.visibleTaskID(c)
+	if !ok {
+		return err
+	}
+
+	body, errs, err := parseComment(c)
+	if err != nil {
+		return validation.InvalidBody(c)
+	}
+	if len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+
+	comment := models.Comment{
+		TaskID:    taskID,
+		AuthorID:  middleware.CurrentPrincipal(c).User.ID,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}
+	if err := h.stores.Comments.Create(c.UserContext(), &comment); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create comment"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionCommentCreate, TargetType: audit.TargetComment, TargetID: comment.ID, After: comment})
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Comment created successfully", "comment": comment})
+}
+
+// UpdateComment replaces the body of a comment. Only its author may edit it.
+func (h *TaskController) UpdateComment(c *fiber.Ctx) error {
+	taskID, ok, err := h.visibleTaskID(c)
+	if !ok {
+		return err
+	}
+	commentID, err := primitive.ObjectIDFromHex(c.Params("commentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid comment ID"})
+	}
+
+	body, errs, err := parseComment(c)
+	if err != nil {
+		return validation.InvalidBody(c)
+	}
+	if len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+
+	ctx := c.UserContext()
+	before, err := h.stores.Comments.FindByID(ctx, taskID, commentID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Comment not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve comment"})
+	}
+	if before.AuthorID != middleware.CurrentPrincipal(c).User.ID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You can only edit your own comments"})
+	}
+
+	comment, err := h.stores.Comments.Update(ctx, taskID, commentID, body, time.Now())
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Comment not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update comment"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionCommentUpdate, TargetType: audit.TargetComment, TargetID: commentID, Before: before, After: comment})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Comment updated successfully", "comment": comment})
+}
+
+// DeleteComment removes a comment. Authors may delete their own comments;
+// holders of moderate_comments may delete any.
+func (h *TaskController) DeleteComment(c *fiber.Ctx) error {
+	taskID, ok, err := h.visibleTaskID(c)
+	if !ok {
+		return err
+	}
+	commentID, err := primitive.ObjectIDFromHex(c.Params("commentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid comment ID"})
+	}
+
+	ctx := c.UserContext()
+	before, err := h.stores.Comments.FindByID(ctx, taskID, commentID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Comment not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve comment"})
+	}
+
+	isAuthor := before.AuthorID == middleware.CurrentPrincipal(c).User.ID
+	project := middleware.CurrentProject(c)
+	if !(isAuthor && project.Can("comment_task")) && !project.Can("moderate_comments") {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You can only delete your own comments"})
+	}
+
+	err = h.stores.Comments.Delete(ctx, taskID, commentID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Comment not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete comment"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionCommentDelete, TargetType: audit.TargetComment, TargetID: commentID, Before: before})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Comment deleted successfully"})
+}
+
+// GetTaskActivity merges the comments on a task with its recorded versions
+// into a single feed, newest first. Each change lists the fields that
+// differ from the previous version.
+func (h *TaskController) GetTaskActivity(c *fiber.Ctx) error {
+	taskID, ok, err := h.visibleTaskID(c)
+	if !ok {
+		return err
+	}
+
+	ctx := c.UserContext()
+	comments, err := h.stores.Comments.List(ctx, taskID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve comments"})
+	}
+	versions, err := h.stores.TaskHistory.List(ctx, taskID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve task history"})
+	}
+
+	byVersion := make(map[int]models.Task, len(versions))
+	for _, version := range versions {
+		byVersion[version.Version] = version.Task
+	}
+
+	activity := make([]activityEntry, 0, len(comments)+len(versions))
+	for i := range comments {
+		comment := comments[i]
+		activity = append(activity, activityEntry{
+			Type:      activityComment,
+			ActorID:   comment.AuthorID,
+			CreatedAt: comment.CreatedAt,
+			Comment:   &comment,
+		})
+	}
+	for _, version := range versions {
+		entry := activityEntry{
+			Type:      activityChange,
+			ActorID:   version.ChangedBy,
+			CreatedAt: version.CreatedAt,
+			Change:    version.Change,
+			Version:   version.Version,
+		}
+		if previous, ok := byVersion[version.Version-1]; ok {
+			entry.Fields = audit.Diff(previous, version.Task)
+			for _, name := range activityIgnoredFields {
+				delete(entry.Fields, name)
+			}
+		}
+		activity = append(activity, entry)
+	}
+
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].CreatedAt.After(activity[j].CreatedAt)
+	})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"activity": activity})
+}
@@ -1,343 +1,408 @@
 package controllers
 
 import (
-	"context"
 	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/audit"
+	"backend/internal/config"
+	"backend/internal/middleware"
 	"backend/internal/models"
-	"backend/internal/database"
+	"backend/internal/storage"
+	"backend/internal/store"
+	"backend/internal/validation"
+	"backend/internal/workflow"
+
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"github.com/golang-jwt/jwt/v5"
-	"os"
-	"time"
 )
 
+// TaskController serves the task CRUD endpoints.
+type TaskController struct {
+	stores      *store.Stores
+	audit       *audit.Logger
+	workflow    workflow.Workflow
+	files       storage.Storage
+	attachments config.Attachments
+}
 
-func ParseJWT(token string) (*models.CustomClaims, error) {
-	secretKey := os.Getenv("JWT_SECRET_KEY")
-
-	claims := &models.CustomClaims{}
-    parsedToken, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, errors.New("unexpected signing method")
-        }
-        return []byte(secretKey), nil
-    })
+func NewTaskController(stores *store.Stores, auditLog *audit.Logger, flow workflow.Workflow, files storage.Storage, attachments config.Attachments) *TaskController {
+	return &TaskController{stores: stores, audit: auditLog, workflow: flow, files: files, attachments: attachments}
+}
 
-    if err != nil {
-        return nil, err
-    }
+const maxLabelLength = 50
 
-    
-    if !parsedToken.Valid {
-        return nil, errors.New("invalid token")
-    }
+// taskScope limits task access to the project in the path and to what the
+// caller may see there. Callers without view_all_task in the project only
+// see tasks they created or are assigned to.
+func taskScope(c *fiber.Ctx) store.TaskScope {
+	project := middleware.CurrentProject(c)
+	scope := store.TaskScope{ProjectID: &project.Project.ID}
+	if project.Can("view_all_task") {
+		return scope
+	}
 
-    return claims, nil
+	userID := middleware.CurrentPrincipal(c).User.ID
+	scope.VisibleTo = &userID
+	return scope
 }
 
+var unknownAssigneeErrors = validation.Errors{
+	{Field: "assignees", Code: validation.CodeNotFound, Message: "must only reference project members"},
+}
 
-func getUserIDFromToken(c *fiber.Ctx) (primitive.ObjectID, error) {
-	
-	token := c.Cookies("jwt")
-	if token == "" {
-		return primitive.ObjectID{}, errors.New("Authorization token is missing")
+// validateAssignees reports whether every ID refers to a member of the
+// project in the path.
+func (h *TaskController) validateAssignees(c *fiber.Ctx, assignees []primitive.ObjectID) (bool, error) {
+	if len(assignees) == 0 {
+		return true, nil
 	}
 
-	
-	
-	claims, err := ParseJWT(token)
-	if err != nil {
-		return primitive.ObjectID{}, err
+	unique := map[primitive.ObjectID]bool{}
+	for _, id := range assignees {
+		unique[id] = true
 	}
 
-	
-	userID, err := primitive.ObjectIDFromHex(claims.Issuer) 
+	count, err := h.stores.Members.CountMembers(c.UserContext(), middleware.CurrentProject(c).Project.ID, assignees)
 	if err != nil {
-		return primitive.ObjectID{}, errors.New("Invalid user ID in token")
+		return false, err
 	}
-
-	return userID, nil
+	return count == int64(len(unique)), nil
 }
 
-func GetTasks(c *fiber.Ctx) error {
-    userID, err := getUserIDFromToken(c)
-    if err != nil {
-        return err
-    }
-
-    
-    userCollection := database.GetCollection("users")
-    var user models.User
-    err = userCollection.FindOne(context.Background(), bson.M{"_id": userID}).Decode(&user)
-    if err != nil {
-        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
-    }
-
-    
-    roleCollection := database.GetCollection("roles")
-    var role models.Role
-    err = roleCollection.FindOne(context.Background(), bson.M{"_id": user.RoleID}).Decode(&role)
-    if err != nil {
-        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Role not found"})
-    }
-
-    
-    permissionCollection := database.GetCollection("permissions")
-    var permissions []models.Permission
-    cursor, err := permissionCollection.Find(context.Background(), bson.M{"_id": bson.M{"$in": role.Permissions}})
-    if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve permissions"})
-    }
-    defer cursor.Close(context.Background())
-
-    
-    if err := cursor.All(context.Background(), &permissions); err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to decode permissions"})
-    }
-
-    hasPermission := false
-    for _, permission := range permissions {
-        if permission.Name == "view_task" { 
-            hasPermission = true
-            break
-        }
-    }
-
-    if !hasPermission {
-        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You do not have permission to view tasks"})
-    }
-
-
-    taskCollection := database.GetCollection("tasks")
-    cursor, err = taskCollection.Find(context.Background(), bson.M{})
-    if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve tasks"})
-    }
-    defer cursor.Close(context.Background())
-
-    var tasks []models.Task
-    if err := cursor.All(context.Background(), &tasks); err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to decode tasks"})
-    }
-
-    return c.Status(fiber.StatusOK).JSON(fiber.Map{"tasks": tasks})
+// normalizeLabels trims, lower-cases and de-duplicates labels, keeping their
+// order.
+func normalizeLabels(labels []string) ([]string, validation.Errors) {
+	normalized := make([]string, 0, len(labels))
+	seen := map[string]bool{}
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if label == "" || utf8.RuneCountInString(label) > maxLabelLength {
+			return nil, validation.Errors{{Field: "labels", Code: validation.CodeInvalidValue, Message: "each label must be between 1 and 50 characters long"}}
+		}
+		if !seen[label] {
+			seen[label] = true
+			normalized = append(normalized, label)
+		}
+	}
+	return normalized, nil
 }
 
+// statusErrors reports a status that is not part of the workflow.
+func (h *TaskController) statusErrors(status string) validation.Errors {
+	if h.workflow.Valid(status) {
+		return nil
+	}
+	return validation.Errors{{Field: "status", Code: validation.CodeInvalidValue, Message: "must be one of: " + strings.Join(h.workflow.Statuses(), ", ")}}
+}
 
-func CreateTask(c *fiber.Ctx) error {
-    var task models.Task
-    if err := c.BodyParser(&task); err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
-    }
-
-    
-    token := c.Cookies("jwt")
-    if token == "" {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Authorization token is missing"})
-    }
-
-    
-    claims, err := ParseJWT(token)
-    if err != nil {
-        return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
-    }
+// GetWorkflow describes the task statuses and the allowed transitions.
+func (h *TaskController) GetWorkflow(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"workflow": h.workflow})
+}
 
-    userID, err := primitive.ObjectIDFromHex(claims.Issuer)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid user ID in token",
-		})
-	}
-
-    
-    userCollection := database.GetCollection("users")
-    var user models.User
-    err = userCollection.FindOne(context.Background(), bson.M{"_id": userID}).Decode(&user)
-    if err != nil {
-        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
-    }
-
-    
-    roleCollection := database.GetCollection("roles")
-    var role models.Role
-    err = roleCollection.FindOne(context.Background(), bson.M{"_id": user.RoleID}).Decode(&role)
-    if err != nil {
-        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Role not found"})
-    }
-
-    
-    permissionCollection := database.GetCollection("permissions")
-    var permissions []models.Permission
-    cursor, err := permissionCollection.Find(context.Background(), bson.M{"_id": bson.M{"$in": role.Permissions}})
-    if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve permissions"})
-    }
-    defer cursor.Close(context.Background())
-
-    if err := cursor.All(context.Background(), &permissions); err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to decode permissions"})
-    }
-
-
-    hasPermission := false
-    for _, permission := range permissions {
-        if permission.Name == "create_task" { 
-            hasPermission = true
-            break
-        }
-    }
-
-    if !hasPermission {
-        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You do not have permission to create a task"})
-    }
-
-    
-    task.CreatedAt = time.Now()
-    task.UpdatedAt = time.Now()
-    collection := database.GetCollection("tasks")
-    _, err = collection.InsertOne(context.Background(), task)
-    if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create task"})
-    }
-
-    return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Task created successfully", "task": task})
+// GetTasks returns one page of the tasks visible to the caller. The
+// response carries the total number of matching tasks and, when more remain,
+// an opaque next_cursor to pass back as the cursor parameter.
+func (h *TaskController) GetTasks(c *fiber.Ctx) error {
+	return h.listTasks(c, false)
 }
 
+// GetTrash lists deleted tasks visible to the caller that have not yet been
+// purged. It accepts the same query parameters as GetTasks.
+func (h *TaskController) GetTrash(c *fiber.Ctx) error {
+	return h.listTasks(c, true)
+}
 
-func UpdateTask(c *fiber.Ctx) error {
-	taskID := c.Params("id")
-	var taskUpdate models.Task
-	if err := c.BodyParser(&taskUpdate); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+func (h *TaskController) listTasks(c *fiber.Ctx, trash bool) error {
+	filter, page, err := parseTaskQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if filter.Status != "" && !h.workflow.Valid(filter.Status) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "status must be one of " + strings.Join(h.workflow.Statuses(), ", ")})
 	}
+	filter.TaskScope = taskScope(c)
+	filter.Trash = trash
 
-	userID, err := getUserIDFromToken(c)
+	ctx := c.UserContext()
+	total, err := h.stores.Tasks.Count(ctx, filter)
 	if err != nil {
-		return err
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to count tasks"})
 	}
 
-	
-	userCollection := database.GetCollection("users")
-	var user models.User
-	err = userCollection.FindOne(context.Background(), bson.M{"_id": userID}).Decode(&user)
+	// Fetch one extra task to learn whether another page follows.
+	limit := page.Limit
+	page.Limit++
+	tasks, err := h.stores.Tasks.List(ctx, filter, page)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve tasks"})
 	}
 
-	
-	roleCollection := database.GetCollection("roles")
-	var role models.Role
-	err = roleCollection.FindOne(context.Background(), bson.M{"_id": user.RoleID}).Decode(&role)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Role not found"})
+	var nextCursor string
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+		nextCursor = nextTaskCursor(tasks[len(tasks)-1], page.SortField)
 	}
 
-	
-	permissionCollection := database.GetCollection("permissions")
-	var permissions []models.Permission
-	cursor, err := permissionCollection.Find(context.Background(), bson.M{"_id": bson.M{"$in": role.Permissions}})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"tasks":       tasks,
+		"total":       total,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (h *TaskController) GetTask(c *fiber.Ctx) error {
+	taskObjectID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve permissions"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
 	}
-	defer cursor.Close(context.Background())
 
-	if err := cursor.All(context.Background(), &permissions); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to decode permissions"})
+	task, err := h.stores.Tasks.FindByID(c.UserContext(), taskObjectID, taskScope(c))
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
 	}
-
-	
-	hasPermission := false
-	for _, permission := range permissions {
-		if permission.Name == "update_task" { 
-			hasPermission = true
-			break
-		}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve task"})
 	}
 
-	if !hasPermission {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You do not have permission to update this task"})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"task": task})
+}
+
+func (h *TaskController) CreateTask(c *fiber.Ctx) error {
+	var task models.Task
+	if err := c.BodyParser(&task); err != nil {
+		return validation.InvalidBody(c)
+	}
+	if errs := validation.Struct(task); len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+	if task.Status == "" {
+		task.Status = h.workflow.Initial
+	} else if errs := h.statusErrors(task.Status); len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+	if task.Priority == "" {
+		task.Priority = models.PriorityMedium
+	}
+	if task.Labels != nil {
+		labels, errs := normalizeLabels(task.Labels)
+		if len(errs) > 0 {
+			return validation.Respond(c, errs)
+		}
+		task.Labels = labels
 	}
 
-	
-	taskObjectID, err := primitive.ObjectIDFromHex(taskID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
+	ctx := c.UserContext()
+	if ok, err := h.validateAssignees(c, task.Assignees); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to validate assignees"})
+	} else if !ok {
+		return validation.Respond(c, unknownAssigneeErrors)
 	}
 
-	
-	update := bson.M{"$set": taskUpdate}
-	collection := database.GetCollection("tasks")
-	_, err = collection.UpdateOne(context.Background(), bson.M{"_id": taskObjectID}, update)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update task"})
+	task.ID = primitive.NilObjectID
+	task.ProjectID = middleware.CurrentProject(c).Project.ID
+	task.CreatedBy = middleware.CurrentPrincipal(c).User.ID
+	task.CreatedAt = time.Now()
+	task.UpdatedAt = time.Now()
+	task.Version = 1
+	task.DeletedAt = nil
+	task.DeletedBy = nil
+	if err := h.stores.Tasks.Create(ctx, &task); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create task"})
 	}
+	h.recordVersion(c, models.TaskChangeCreate, task, task.Version)
+	h.audit.Record(c, audit.Event{Action: audit.ActionTaskCreate, TargetType: audit.TargetTask, TargetID: task.ID, After: task})
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Task updated successfully" , "data":update})
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Task created successfully", "task": task})
 }
 
-func DeleteTask(c *fiber.Ctx) error {
+// UpdateTask replaces the name and description of a task, and the status,
+// priority, due date, labels and assignees when they are given. The creator
+// and creation time are never changed and updated_at is set by the server.
+func (h *TaskController) UpdateTask(c *fiber.Ctx) error {
 	taskID := c.Params("id")
+	var taskUpdate models.Task
+	if err := c.BodyParser(&taskUpdate); err != nil {
+		return validation.InvalidBody(c)
+	}
 
-	userID, err := getUserIDFromToken(c)
+	taskObjectID, err := primitive.ObjectIDFromHex(taskID)
 	if err != nil {
-		return err
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
 	}
 
-	
-	userCollection := database.GetCollection("users")
-	var user models.User
-	err = userCollection.FindOne(context.Background(), bson.M{"_id": userID}).Decode(&user)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	if errs := validation.Struct(taskUpdate); len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+	if ok, err := h.validateAssignees(c, taskUpdate.Assignees); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to validate assignees"})
+	} else if !ok {
+		return validation.Respond(c, unknownAssigneeErrors)
 	}
 
-	
-	roleCollection := database.GetCollection("roles")
-	var role models.Role
-	err = roleCollection.FindOne(context.Background(), bson.M{"_id": user.RoleID}).Decode(&role)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Role not found"})
+	changes := store.TaskChanges{
+		Name:        &taskUpdate.Name,
+		Description: &taskUpdate.Description,
+	}
+	if taskUpdate.Status != "" {
+		if errs := h.statusErrors(taskUpdate.Status); len(errs) > 0 {
+			return validation.Respond(c, errs)
+		}
+		changes.Status = &taskUpdate.Status
+	}
+	if taskUpdate.Priority != "" {
+		changes.Priority = &taskUpdate.Priority
+	}
+	if taskUpdate.DueDate != nil {
+		changes.DueDate = taskUpdate.DueDate
+	}
+	if taskUpdate.Labels != nil {
+		labels, errs := normalizeLabels(taskUpdate.Labels)
+		if len(errs) > 0 {
+			return validation.Respond(c, errs)
+		}
+		changes.Labels = &labels
 	}
+	if taskUpdate.Assignees != nil {
+		changes.Assignees = &taskUpdate.Assignees
+	}
+
+	return h.saveTaskUpdate(c, taskObjectID, changes, models.TaskChangeUpdate)
+}
 
-	
-	permissionCollection := database.GetCollection("permissions")
-	var permissions []models.Permission
-	cursor, err := permissionCollection.Find(context.Background(), bson.M{"_id": bson.M{"$in": role.Permissions}})
+// PatchTask updates only the fields present in the request body. Unknown
+// and immutable fields are rejected.
+func (h *TaskController) PatchTask(c *fiber.Ctx) error {
+	taskObjectID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve permissions"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
 	}
-	defer cursor.Close(context.Background())
 
-	if err := cursor.All(context.Background(), &permissions); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to decode permissions"})
+	changes, errs := parseTaskPatch(c.Body())
+	if len(errs) > 0 {
+		return validation.Respond(c, errs)
+	}
+	if changes == (store.TaskChanges{}) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Nothing to update"})
+	}
+	if changes.Status != nil {
+		if errs := h.statusErrors(*changes.Status); len(errs) > 0 {
+			return validation.Respond(c, errs)
+		}
 	}
 
-	
-	hasPermission := false
-	for _, permission := range permissions {
-		if permission.Name == "delete_task" { 
-			hasPermission = true
-			break
+	if changes.Assignees != nil {
+		if ok, err := h.validateAssignees(c, *changes.Assignees); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to validate assignees"})
+		} else if !ok {
+			return validation.Respond(c, unknownAssigneeErrors)
 		}
 	}
 
-	if !hasPermission {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You do not have permission to delete this task"})
+	return h.saveTaskUpdate(c, taskObjectID, changes, models.TaskChangeUpdate)
+}
+
+// saveTaskUpdate applies changes to a task visible to the caller, stamps
+// updated_at, records the new version and responds with the updated
+// document. change is models.TaskChangeUpdate, for which status changes must
+// follow the workflow, or models.TaskChangeRevert, for which they need not.
+func (h *TaskController) saveTaskUpdate(c *fiber.Ctx, taskID primitive.ObjectID, changes store.TaskChanges, change string) error {
+	ctx := c.UserContext()
+	scope := taskScope(c)
+	changes.UpdatedAt = time.Now()
+
+	// The current version is read first so the audit entry can record what
+	// changed.
+	var task *models.Task
+	before, err := h.stores.Tasks.FindByID(ctx, taskID, scope)
+	if err == nil && change == models.TaskChangeUpdate && changes.Status != nil && !h.workflow.Allowed(before.Status, *changes.Status) {
+		return validation.Respond(c, validation.Errors{{
+			Field:   "status",
+			Code:    validation.CodeInvalidTransition,
+			Message: "cannot move from " + before.Status + " to " + *changes.Status,
+		}})
+	}
+	if err == nil {
+		if change == models.TaskChangeUpdate && changes.Status != nil {
+			changes.ExpectedStatus = &before.Status
+		}
+		task, err = h.stores.Tasks.Update(ctx, taskID, scope, changes)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
 	}
+	if errors.Is(err, store.ErrConflict) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "The task's status changed while it was being updated; reload it and try again"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update task"})
+	}
+	h.recordVersion(c, change, *task, task.Version)
+
+	action, message := audit.ActionTaskUpdate, "Task updated successfully"
+	if change == models.TaskChangeRevert {
+		action, message = audit.ActionTaskRevert, "Task reverted successfully"
+	}
+	h.audit.Record(c, audit.Event{Action: action, TargetType: audit.TargetTask, TargetID: taskID, Before: before, After: task})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": message, "task": task})
+}
+
+// DeleteTask moves a task to the trash. It can be restored until the purge
+// job removes it.
+func (h *TaskController) DeleteTask(c *fiber.Ctx) error {
+	taskID := c.Params("id")
 
-	
 	taskObjectID, err := primitive.ObjectIDFromHex(taskID)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
 	}
 
-	collection := database.GetCollection("tasks")
-	_, err = collection.DeleteOne(context.Background(), bson.M{"_id": taskObjectID})
+	ctx := c.UserContext()
+	scope := taskScope(c)
+	var task *models.Task
+	before, err := h.stores.Tasks.FindByID(ctx, taskObjectID, scope)
+	if err == nil {
+		task, err = h.stores.Tasks.Delete(ctx, taskObjectID, scope, middleware.CurrentPrincipal(c).User.ID, time.Now())
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete task"})
 	}
+	h.recordVersion(c, models.TaskChangeDelete, *task, task.Version)
+	h.audit.Record(c, audit.Event{Action: audit.ActionTaskDelete, TargetType: audit.TargetTask, TargetID: taskObjectID, Before: before, After: task})
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Task deleted successfully"})
 }
+
+// RestoreTask moves a task out of the trash.
+func (h *TaskController) RestoreTask(c *fiber.Ctx) error {
+	taskObjectID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
+	}
+
+	ctx := c.UserContext()
+	scope := taskScope(c)
+	scope.Trash = true
+	var task *models.Task
+	before, err := h.stores.Tasks.FindByID(ctx, taskObjectID, scope)
+	if err == nil {
+		task, err = h.stores.Tasks.Restore(ctx, taskObjectID, scope, time.Now())
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found in trash"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to restore task"})
+	}
+	h.recordVersion(c, models.TaskChangeRestore, *task, task.Version)
+	h.audit.Record(c, audit.Event{Action: audit.ActionTaskRestore, TargetType: audit.TargetTask, TargetID: taskObjectID, Before: before, After: task})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Task restored successfully", "task": task})
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+	"backend/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type revertTaskRequest struct {
+	Version int `json:"version"`
+}
+
+// recordVersion stores a snapshot of task under the given version number.
+// The change it describes has already been saved, so a failure is logged
+// rather than reported to the caller.
+func (h *TaskController) recordVersion(c *fiber.Ctx, change string, task models.Task, version int) {
+	snapshot := models.TaskVersion{
+		TaskID:    task.ID,
+		Version:   version,
+		Change:    change,
+		Task:      task,
+		ChangedBy: middleware.CurrentPrincipal(c).User.ID,
+		CreatedAt: time.Now(),
+	}
+	if err := h.stores.TaskHistory.Append(c.UserContext(), &snapshot); err != nil {
+		log.Printf("Failed to record version %d of task %s: %v", version, task.ID.Hex(), err)
+	}
+}
+
+// GetTaskHistory lists every recorded version of a task, newest first.
+func (h *TaskController) GetTaskHistory(c *fiber.Ctx) error {
+	taskObjectID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
+	}
+
+	ctx := c.UserContext()
+	_, err = h.stores.Tasks.FindByID(ctx, taskObjectID, taskScope(c))
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve task"})
+	}
+
+	versions, err := h.stores.TaskHistory.List(ctx, taskObjectID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve task history"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"versions": versions})
+}
+
+// RevertTask restores the editable fields of a task to those of an earlier
+// version. The workflow does not restrict the restored status. The revert is
+// itself saved as a new version, so it can be undone.
+func (h *TaskController) RevertTask(c *fiber.Ctx) error {
+	taskObjectID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
+	}
+
+	var body revertTaskRequest
+	if err := c.BodyParser(&body); err != nil {
+		return validation.InvalidBody(c)
+	}
+	if body.Version < 1 {
+		var errs validation.Errors
+		errs.Add("version", validation.CodeInvalidValue, "must be a positive version number")
+		return validation.Respond(c, errs)
+	}
+
+	ctx := c.UserContext()
+	_, err = h.stores.Tasks.FindByID(ctx, taskObjectID, taskScope(c))
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve task"})
+	}
+
+	snapshot, err := h.stores.TaskHistory.Find(ctx, taskObjectID, body.Version)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Version not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve task history"})
+	}
+	if snapshot.Change == models.TaskChangeDelete {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot revert to a deletion"})
+	}
+
+	previous := snapshot.Task
+	if previous.Assignees == nil {
+		previous.Assignees = []primitive.ObjectID{}
+	}
+	if previous.Labels == nil {
+		previous.Labels = []string{}
+	}
+	changes := store.TaskChanges{
+		Name:         &previous.Name,
+		Description:  &previous.Description,
+		Status:       &previous.Status,
+		Priority:     &previous.Priority,
+		DueDate:      previous.DueDate,
+		ClearDueDate: previous.DueDate == nil,
+		Labels:       &previous.Labels,
+		Assignees:    &previous.Assignees,
+	}
+	return h.saveTaskUpdate(c, taskObjectID, changes, models.TaskChangeRevert)
+}
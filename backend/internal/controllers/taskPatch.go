@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"backend/internal/store"
+	"backend/internal/validation"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// immutableTaskFields are set by the server and may not appear in a patch.
+var immutableTaskFields = map[string]bool{
+	"_id":        true,
+	"created_at": true,
+	"updated_at": true,
+	"created_by": true,
+	"version":    true,
+	"deleted_at": true,
+	"deleted_by": true,
+}
+
+// patchableTaskFields are the fields a PATCH request may change.
+var patchableTaskFields = map[string]bool{
+	"name":        true,
+	"description": true,
+	"status":      true,
+	"priority":    true,
+	"due_date":    true,
+	"labels":      true,
+	"assignees":   true,
+}
+
+// taskPatch holds the fields a PATCH request may change. A nil field was
+// absent from the body, or null, and is left untouched; a null due_date is
+// handled separately and clears the due date.
+type taskPatch struct {
+	Name        *string               `json:"name" validate:"required,max=200"`
+	Description *string               `json:"description" validate:"max=5000"`
+	Status      *string               `json:"status" validate:"required"`
+	Priority    *string               `json:"priority" validate:"required,oneof=low medium high urgent"`
+	DueDate     *time.Time            `json:"due_date"`
+	Labels      *[]string             `json:"labels" validate:"max=20"`
+	Assignees   *[]primitive.ObjectID `json:"assignees" validate:"max=50"`
+}
+
+// parseTaskPatch turns a JSON patch body into the changes to apply. The
+// status is checked against the workflow by the caller.
+func parseTaskPatch(body []byte) (store.TaskChanges, validation.Errors) {
+	var changes store.TaskChanges
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return changes, validation.Errors{{Field: "body", Code: validation.CodeInvalidBody, Message: "must be a JSON object"}}
+	}
+
+	var errs validation.Errors
+	for field := range fields {
+		switch {
+		case immutableTaskFields[field]:
+			errs.Add(field, validation.CodeImmutable, "cannot be modified")
+		case !patchableTaskFields[field]:
+			errs.Add(field, validation.CodeUnknownField, "is not a task field")
+		}
+	}
+	if len(errs) > 0 {
+		return changes, errs
+	}
+
+	var patch taskPatch
+	if err := json.Unmarshal(body, &patch); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return changes, validation.Errors{{Field: typeErr.Field, Code: validation.CodeInvalidType, Message: "must be a " + typeErr.Type.String()}}
+		}
+		return changes, validation.Errors{{Field: "body", Code: validation.CodeInvalidBody, Message: err.Error()}}
+	}
+	if errs := validation.Struct(patch); len(errs) > 0 {
+		return changes, errs
+	}
+
+	if patch.Labels != nil {
+		labels, errs := normalizeLabels(*patch.Labels)
+		if len(errs) > 0 {
+			return changes, errs
+		}
+		patch.Labels = &labels
+	}
+
+	changes.Name = patch.Name
+	changes.Description = patch.Description
+	changes.Status = patch.Status
+	changes.Priority = patch.Priority
+	changes.DueDate = patch.DueDate
+	changes.ClearDueDate = string(fields["due_date"]) == "null"
+	changes.Labels = patch.Labels
+	changes.Assignees = patch.Assignees
+	return changes, nil
+}
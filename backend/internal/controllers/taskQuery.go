@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultTasksPageSize = 50
+	maxTasksPageSize     = 200
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodedTaskCursor is the JSON form of a store.TaskCursor handed to
+// clients as an opaque string.
+type encodedTaskCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// parseTaskQuery reads the GET /api/tasks query string. The returned filter
+// has no scope; callers add it.
+func parseTaskQuery(c *fiber.Ctx) (store.TaskFilter, store.TaskPage, error) {
+	var filter store.TaskFilter
+	page := store.TaskPage{
+		Limit:      c.QueryInt("limit", defaultTasksPageSize),
+		SortField:  store.TaskSortCreatedAt,
+		Descending: true,
+	}
+	if page.Limit < 1 || page.Limit > maxTasksPageSize {
+		return filter, page, errors.New("limit must be between 1 and " + strconv.Itoa(maxTasksPageSize))
+	}
+
+	switch sort := c.Query("sort"); sort {
+	case "":
+	case store.TaskSortCreatedAt, store.TaskSortUpdatedAt, store.TaskSortName:
+		page.SortField = sort
+	default:
+		return filter, page, errors.New("sort must be one of created_at, updated_at, name")
+	}
+	switch c.Query("order", "desc") {
+	case "asc":
+		page.Descending = false
+	case "desc":
+		page.Descending = true
+	default:
+		return filter, page, errors.New("order must be asc or desc")
+	}
+
+	// The status is checked against the workflow by the caller.
+	filter.Status = strings.TrimSpace(c.Query("status"))
+
+	switch priority := c.Query("priority"); priority {
+	case "", models.PriorityLow, models.PriorityMedium, models.PriorityHigh, models.PriorityUrgent:
+		filter.Priority = priority
+	default:
+		return filter, page, errors.New("priority must be one of low, medium, high, urgent")
+	}
+
+	filter.Label = strings.ToLower(strings.TrimSpace(c.Query("label")))
+	filter.Search = strings.TrimSpace(c.Query("q"))
+
+	for param, target := range map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+		"updated_from": &filter.UpdatedFrom,
+		"updated_to":   &filter.UpdatedTo,
+		"due_from":     &filter.DueFrom,
+		"due_to":       &filter.DueTo,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, page, errors.New(param + " must be an RFC 3339 timestamp")
+		}
+		*target = &t
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := decodeTaskCursor(raw, page.SortField)
+		if err != nil {
+			return filter, page, err
+		}
+		page.After = cursor
+	}
+
+	return filter, page, nil
+}
+
+// nextTaskCursor returns the cursor positioned at task.
+func nextTaskCursor(task models.Task, sortField string) string {
+	cursor := encodedTaskCursor{ID: task.ID.Hex()}
+	switch sortField {
+	case store.TaskSortName:
+		cursor.Value = task.Name
+	case store.TaskSortUpdatedAt:
+		cursor.Value = task.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		cursor.Value = task.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeTaskCursor(raw, sortField string) (*store.TaskCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var encoded encodedTaskCursor
+	if err := json.Unmarshal(decoded, &encoded); err != nil {
+		return nil, errInvalidCursor
+	}
+
+	id, err := primitive.ObjectIDFromHex(encoded.ID)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	cursor := &store.TaskCursor{ID: id, Value: encoded.Value}
+	if sortField != store.TaskSortName {
+		t, err := time.Parse(time.RFC3339Nano, encoded.Value)
+		if err != nil {
+			return nil, errInvalidCursor
+		}
+		cursor.Value = t
+	}
+	return cursor, nil
+}
@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const refreshCookieName = "refresh_token"
+
+// Refresh exchanges a refresh token for a new access token and a new
+// refresh token from the same family. Presenting a refresh token that has
+// already been used revokes the whole family. It needs no CSRF token: a
+// forged request only rotates the victim's own cookies, and sessions that
+// predate CSRF protection get their CSRF cookie here.
+func (h *AuthController) Refresh(c *fiber.Ctx) error {
+	refreshToken, _ := refreshTokenFromRequest(c)
+	if refreshToken == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Refresh token is missing"})
+	}
+
+	ctx := c.UserContext()
+	tokenHash := middleware.HashToken(refreshToken)
+
+	current, err := h.stores.Tokens.UseRefreshToken(ctx, tokenHash, time.Now())
+	if errors.Is(err, store.ErrNotFound) {
+		stale, err := h.stores.Tokens.FindRefreshToken(ctx, tokenHash)
+		if err == nil && (stale.Used || stale.Revoked) {
+			if err := h.stores.Tokens.RevokeRefreshFamily(ctx, stale.FamilyID); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke refresh token"})
+			}
+		}
+		clearSessionCookies(c)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired refresh token"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify refresh token"})
+	}
+
+	user, err := h.stores.Users.FindByID(ctx, current.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+	}
+	if user.Disabled {
+		if err := h.stores.Tokens.RevokeRefreshFamily(ctx, current.FamilyID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke refresh token"})
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Account is disabled"})
+	}
+
+	return h.issueSession(c, *user, current.FamilyID, fiber.Map{"message": "Token refreshed"})
+}
+
+// issueSession signs a new access token, stores a new refresh token in the
+// given family and sets both as cookies, along with a fresh CSRF token. The
+// tokens are also added to body, which is sent as the response. Users the
+// MFA policy covers who have not set up two-factor authentication get a
+// session limited to doing so.
+func (h *AuthController) issueSession(c *fiber.Ctx, user models.User, familyID primitive.ObjectID, body fiber.Map) error {
+	enrollment, err := h.needsMFAEnrollment(c.UserContext(), user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check two-factor authentication",
+		})
+	}
+
+	now := time.Now()
+	expirationTime := now.Add(h.cfg.AccessTokenTTL)
+	claims := &models.CustomClaims{
+		Role:          user.RoleID.Hex(),
+		MFAEnrollment: enrollment,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    user.ID.Hex(),
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        generateJTI(),
+		},
+	}
+
+	signedToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(h.cfg.JWTSecret))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to sign the token",
+		})
+	}
+
+	refreshToken, err := generateToken()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate refresh token",
+		})
+	}
+
+	refreshExpirationTime := now.Add(h.cfg.RefreshTokenTTL)
+	err = h.stores.Tokens.CreateRefreshToken(c.UserContext(), &models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: middleware.HashToken(refreshToken),
+		ExpiresAt: refreshExpirationTime,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to store refresh token",
+		})
+	}
+
+	csrfToken, err := generateToken()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate CSRF token",
+		})
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     middleware.AccessTokenCookie,
+		Value:    signedToken,
+		Expires:  expirationTime,
+		HTTPOnly: true,
+		Secure:   false,
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     refreshCookieName,
+		Value:    refreshToken,
+		Path:     "/api",
+		Expires:  refreshExpirationTime,
+		HTTPOnly: true,
+		Secure:   false,
+	})
+	// The frontend reads this cookie and echoes it in middleware.CSRFHeader.
+	c.Cookie(&fiber.Cookie{
+		Name:    middleware.CSRFCookie,
+		Value:   csrfToken,
+		Path:    "/",
+		Expires: refreshExpirationTime,
+		Secure:  false,
+	})
+
+	body["token"] = signedToken
+	body["expires_at"] = expirationTime
+	body["refresh_token"] = refreshToken
+	body["csrf_token"] = csrfToken
+	if enrollment {
+		body["mfa_enrollment_required"] = true
+	}
+	return c.Status(fiber.StatusOK).JSON(body)
+}
+
+func clearSessionCookies(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     middleware.AccessTokenCookie,
+		Value:    "",
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     refreshCookieName,
+		Value:    "",
+		Path:     "/api",
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:    middleware.CSRFCookie,
+		Value:   "",
+		Path:    "/",
+		Expires: time.Now().Add(-time.Hour),
+	})
+}
+
+// refreshTokenFromRequest reads the refresh token from a "refresh_token"
+// field in the request body, falling back to its cookie, and reports where
+// it was found. Like middleware.ExtractToken, it prefers what the client
+// sent explicitly over what the browser attached.
+func refreshTokenFromRequest(c *fiber.Ctx) (string, middleware.TokenSource) {
+	var data map[string]string
+	if err := c.BodyParser(&data); err == nil && data["refresh_token"] != "" {
+		return data["refresh_token"], middleware.TokenExplicit
+	}
+	if token := c.Cookies(refreshCookieName); token != "" {
+		return token, middleware.TokenCookie
+	}
+	return "", middleware.TokenNone
+}
+
+// revokeAccessToken adds the token's jti to the denylist until it expires.
+func (h *AuthController) revokeAccessToken(ctx context.Context, claims *models.CustomClaims) error {
+	if claims.ID == "" {
+		return nil
+	}
+
+	expiresAt := time.Now().Add(h.cfg.AccessTokenTTL)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	return h.stores.Tokens.RevokeAccessToken(ctx, models.RevokedToken{
+		JTI:       claims.ID,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// revokeRefreshFamily revokes every refresh token sharing a family with the
+// given token. Unknown tokens are ignored.
+func (h *AuthController) revokeRefreshFamily(ctx context.Context, refreshToken string) error {
+	token, err := h.stores.Tokens.FindRefreshToken(ctx, middleware.HashToken(refreshToken))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return h.stores.Tokens.RevokeRefreshFamily(ctx, token.FamilyID)
+}
+
+// generateToken returns a random, URL-safe token with 256 bits of entropy.
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"backend/internal/audit"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+	"backend/internal/throttle"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultUsersPageSize = 20
+	maxUsersPageSize     = 100
+)
+
+// UserController lets administrators inspect and manage user accounts.
+type UserController struct {
+	stores  *store.Stores
+	audit   *audit.Logger
+	limiter *throttle.LoginLimiter
+}
+
+func NewUserController(stores *store.Stores, auditLog *audit.Logger, limiter *throttle.LoginLimiter) *UserController {
+	return &UserController{stores: stores, audit: auditLog, limiter: limiter}
+}
+
+// ListUsers returns one page of users, optionally filtered by a
+// case-insensitive search on name or email.
+func (h *UserController) ListUsers(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit := c.QueryInt("limit", defaultUsersPageSize)
+	if limit < 1 || limit > maxUsersPageSize {
+		limit = defaultUsersPageSize
+	}
+
+	filter := store.UserFilter{Search: strings.TrimSpace(c.Query("search"))}
+	users, total, err := h.stores.Users.List(c.UserContext(), filter, (page-1)*limit, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve users"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"users": users,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+	})
+}
+
+type userUpdateRequest struct {
+	RoleID   *string `json:"role_id"`
+	Disabled *bool   `json:"disabled"`
+}
+
+// UpdateUser reassigns a user's role and/or toggles the disabled flag.
+// Only the fields present in the body are changed.
+func (h *UserController) UpdateUser(c *fiber.Ctx) error {
+	userID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	var body userUpdateRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	if body.RoleID == nil && body.Disabled == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Nothing to update"})
+	}
+
+	ctx := c.UserContext()
+	changes := store.UserChanges{Disabled: body.Disabled}
+	if body.RoleID != nil {
+		roleID, err := primitive.ObjectIDFromHex(*body.RoleID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid role ID"})
+		}
+
+		_, err = h.stores.Roles.FindByID(ctx, roleID)
+		if errors.Is(err, store.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Role not found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve role"})
+		}
+		changes.RoleID = &roleID
+	}
+	self := userID == middleware.CurrentPrincipal(c).User.ID
+	if body.Disabled != nil && *body.Disabled && self {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "You cannot disable your own account"})
+	}
+
+	before, err := h.stores.Users.FindByID(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve user"})
+	}
+	if changes.RoleID != nil && *changes.RoleID != before.RoleID && self {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "You cannot change your own role"})
+	}
+	last, err := h.lastHolder(ctx, *before, changes)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check permissions"})
+	}
+	if last != "" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "This is the last enabled user holding " + last + "; grant it to someone else first",
+		})
+	}
+
+	user, err := h.stores.Users.Update(ctx, userID, changes)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update user"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionUserUpdate, TargetType: audit.TargetUser, TargetID: userID, Before: before, After: user})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "User updated successfully", "user": user})
+}
+
+// lastHolder returns the name of one of the lockoutPermissions that user is
+// the last enabled holder of and would lose through changes, or "" when the
+// changes leave every such permission held by someone.
+func (h *UserController) lastHolder(ctx context.Context, user models.User, changes store.UserChanges) (string, error) {
+	if user.Disabled {
+		return "", nil
+	}
+	disabling := changes.Disabled != nil && *changes.Disabled
+	newRole := user.RoleID
+	if changes.RoleID != nil {
+		newRole = *changes.RoleID
+	}
+	if !disabling && newRole == user.RoleID {
+		return "", nil
+	}
+
+	for _, name := range lockoutPermissions {
+		permission, err := h.stores.Permissions.FindByName(ctx, name)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		holders, err := rolesGranting(ctx, h.stores, permission.ID)
+		if err != nil {
+			return "", err
+		}
+		if !slices.Contains(holders, user.RoleID) || (!disabling && slices.Contains(holders, newRole)) {
+			continue
+		}
+		// The count includes user, who is enabled and currently holds it.
+		count, err := h.stores.Users.CountEnabledByRoles(ctx, holders)
+		if err != nil {
+			return "", err
+		}
+		if count <= 1 {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// UnlockUser clears the failed logins recorded for a user's email, lifting
+// a lockout before it expires. Throttling of the client IPs involved is
+// left in place.
+func (h *UserController) UnlockUser(c *fiber.Ctx) error {
+	userID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	ctx := c.UserContext()
+	user, err := h.stores.Users.FindByID(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve user"})
+	}
+	if err := h.limiter.Reset(ctx, user.Email); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to unlock user"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionUserUnlock, TargetType: audit.TargetUser, TargetID: userID})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "User unlocked successfully"})
+}
+
+// ResetMFA removes a user's two-factor authentication, for users who lost
+// both their authenticator and their recovery codes. If the MFA policy
+// covers them they must set it up again at their next login.
+func (h *UserController) ResetMFA(c *fiber.Ctx) error {
+	userID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	ctx := c.UserContext()
+	_, err = h.stores.Users.FindByID(ctx, userID)
+	if err == nil {
+		err = h.stores.MFA.Delete(ctx, userID)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Two-factor authentication is not set up for this user"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to reset two-factor authentication"})
+	}
+	h.audit.Record(c, audit.Event{Action: audit.ActionMFAReset, TargetType: audit.TargetUser, TargetID: userID})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Two-factor authentication reset successfully"})
+}
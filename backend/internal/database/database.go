@@ -2,9 +2,15 @@ package database
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
-	"os"
+	"strings"
 
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -12,18 +18,12 @@ import (
 var (
 	client     *mongo.Client
 	database   *mongo.Database
-	dbName     = "golang_db" 
 )
 
 
-func Connect() *mongo.Client {
-
-	MONGODB_URI := os.Getenv("MONGODB_URI")
-	if MONGODB_URI == "" {
-		log.Fatal("MONGODB_URI environment variable not set")
-	}
+func Connect(cfg config.Database) *mongo.Client {
 
-	clientOptions := options.Client().ApplyURI(MONGODB_URI)
+	clientOptions := options.Client().ApplyURI(cfg.URI)
 
 	var err error
 	client, err = mongo.Connect(context.Background(), clientOptions)
@@ -36,14 +36,263 @@ func Connect() *mongo.Client {
 		log.Fatal("Failed to ping MongoDB:", err)
 	}
 
-	database = client.Database(dbName)
+	database = client.Database(cfg.Name)
 	log.Println("Successfully connected to MongoDB")
 	return client
 }
 
+// Ping checks that the MongoDB deployment is reachable.
+func Ping(ctx context.Context) error {
+	if client == nil {
+		return errors.New("database connection not initialized")
+	}
+	return client.Ping(ctx, nil)
+}
+
+// Disconnect closes the MongoDB client, waiting for in-progress operations
+// until ctx expires.
+func Disconnect(ctx context.Context) error {
+	if client == nil {
+		return nil
+	}
+	return client.Disconnect(ctx)
+}
+
+// GetDatabase returns the application database.
+func GetDatabase() *mongo.Database {
+	if database == nil {
+		log.Fatal("Database connection not initialized")
+	}
+	return database
+}
+
 func GetCollection(name string) *mongo.Collection {
 	if database == nil {
 		log.Fatal("Database connection not initialized")
 	}
 	return database.Collection(name)
 }
+
+// EmailCollation compares email addresses case-insensitively. Queries on
+// users.email must use it to match the unique index.
+var EmailCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// EnsureIndexes creates the indexes the application relies on. Failing to
+// create the unique email index is fatal, because without it duplicate
+// accounts can still be registered and Login may pick any of them; the
+// error lists the emails that must be resolved first. Other failures are
+// logged.
+func EnsureIndexes() {
+	indexes := map[string][]mongo.IndexModel{
+		"users": {
+			{
+				Keys:    bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().SetName("email_unique").SetUnique(true).SetCollation(EmailCollation),
+			},
+		},
+		"refresh_tokens": {
+			{
+				Keys:    bson.D{{Key: "token_hash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "family_id", Value: 1}},
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+		"tasks": {
+			{
+				Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "created_at", Value: -1}},
+			},
+			{
+				Keys: bson.D{{Key: "deleted_at", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "status", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "labels", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "due_date", Value: 1}},
+			},
+		},
+		"project_members": {
+			{
+				Keys:    bson.D{{Key: "project_id", Value: 1}, {Key: "user_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "user_id", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "role_id", Value: 1}},
+			},
+		},
+		"task_history": {
+			{
+				Keys:    bson.D{{Key: "task_id", Value: 1}, {Key: "version", Value: -1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+		"task_comments": {
+			{
+				Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "created_at", Value: 1}},
+			},
+		},
+		"task_attachments": {
+			{
+				Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "created_at", Value: 1}},
+			},
+		},
+		"audit_log": {
+			{
+				Keys: bson.D{{Key: "created_at", Value: -1}},
+			},
+			{
+				Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}},
+			},
+			{
+				Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}},
+			},
+		},
+		"account_tokens": {
+			{
+				Keys:    bson.D{{Key: "token_hash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}},
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+		"api_tokens": {
+			{
+				Keys:    bson.D{{Key: "token_hash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+		"login_attempts": {
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+		"revoked_tokens": {
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	}
+
+	for collection, indexModels := range indexes {
+		_, err := GetCollection(collection).Indexes().CreateMany(context.Background(), indexModels)
+		if err == nil {
+			continue
+		}
+		if collection == "users" {
+			log.Fatal(emailIndexError(err))
+		}
+		log.Printf("Failed to create indexes on %s: %v", collection, err)
+	}
+}
+
+// emailIndexError explains why the unique email index could not be built,
+// listing the email addresses registered more than once.
+func emailIndexError(err error) error {
+	duplicates, findErr := duplicateEmails(context.Background())
+	if findErr != nil {
+		return fmt.Errorf("failed to create the unique email index: %v (listing duplicate emails also failed: %v)", err, findErr)
+	}
+	if len(duplicates) == 0 {
+		return fmt.Errorf("failed to create the unique email index: %v", err)
+	}
+	return fmt.Errorf("failed to create the unique email index because these emails belong to more than one user; merge or remove the extra accounts and restart: %s", strings.Join(duplicates, ", "))
+}
+
+// duplicateEmails returns the email addresses, compared case-insensitively,
+// that belong to more than one user.
+func duplicateEmails(ctx context.Context) ([]string, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"$toLower": "$email"}, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+	cursor, err := GetCollection("users").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var emails []string
+	for cursor.Next(ctx) {
+		var group struct {
+			Email string `bson:"_id"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return nil, err
+		}
+		emails = append(emails, group.Email)
+	}
+	return emails, cursor.Err()
+}
+
+// MigrateUsers marks accounts created before email verification existed as
+// verified, so that requiring verification does not lock them out. It is
+// safe to run on every startup.
+func MigrateUsers() {
+	result, err := GetCollection("users").UpdateMany(
+		context.Background(),
+		bson.M{"email_verified": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"email_verified": true}},
+	)
+	if err != nil {
+		log.Printf("Failed to migrate users: %v", err)
+		return
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("Marked %d existing users as verified", result.ModifiedCount)
+	}
+}
+
+// MigrateTasks upgrades task documents, and the task snapshots kept in
+// task_history, written before tasks had a workflow status and a priority.
+// The old boolean status maps to the initial and final workflow statuses.
+// It is safe to run on every startup.
+func MigrateTasks(initialStatus, finalStatus string) {
+	for collection, prefix := range map[string]string{"tasks": "", "task_history": "task."} {
+		updates := []struct {
+			filter bson.M
+			set    bson.M
+		}{
+			{bson.M{prefix + "status": false}, bson.M{prefix + "status": initialStatus}},
+			{bson.M{prefix + "status": true}, bson.M{prefix + "status": finalStatus}},
+			{bson.M{prefix + "priority": bson.M{"$exists": false}}, bson.M{prefix + "priority": models.PriorityMedium}},
+		}
+
+		for _, update := range updates {
+			result, err := GetCollection(collection).UpdateMany(context.Background(), update.filter, bson.M{"$set": update.set})
+			if err != nil {
+				log.Printf("Failed to migrate %s: %v", collection, err)
+				continue
+			}
+			if result.ModifiedCount > 0 {
+				log.Printf("Migrated %d documents in %s to %v", result.ModifiedCount, collection, update.set)
+			}
+		}
+	}
+}
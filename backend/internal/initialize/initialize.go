@@ -0,0 +1,207 @@
+package initialize
+
+import (
+	"backend/internal/models"
+	"backend/internal/store"
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func InitializePermissionsAndRoles(stores *store.Stores) {
+	ctx := context.Background()
+
+	permissions := []models.Permission{
+		{Name: "view_own_task", Description: "Allows viewing tasks the user created or is assigned to"},
+		{Name: "view_all_task", Description: "Allows viewing every task"},
+		{Name: "create_task", Description: "Allows creating tasks"},
+		{Name: "update_task", Description: "Allows updating tasks"},
+		{Name: "delete_task", Description: "Allows deleting tasks"},
+		{Name: "comment_task", Description: "Allows commenting on visible tasks and editing or deleting one's own comments"},
+		{Name: "moderate_comments", Description: "Allows deleting any comment"},
+		{Name: "manage_roles", Description: "Allows managing roles and their permissions"},
+		{Name: "manage_users", Description: "Allows listing users, changing their role and disabling accounts"},
+		{Name: "view_audit_log", Description: "Allows querying the audit log"},
+		{Name: "create_project", Description: "Allows creating projects"},
+		{Name: "manage_project", Description: "Allows renaming a project and managing its members"},
+		{Name: "administer_projects", Description: "Allows full access to every project without being a member"},
+	}
+
+	roles := []models.Role{
+		{Name: models.RoleAdmin, Permissions: []primitive.ObjectID{}},
+		{Name: models.RoleUser, Permissions: []primitive.ObjectID{}},
+	}
+
+	// Permissions granted to each seeded role. The admin role receives
+	// every permission.
+	userDefaults := map[string]bool{"view_own_task": true, "comment_task": true}
+
+	// Permissions inserted during this run are granted to the seeded roles
+	// even when those roles already exist, so upgrades pick them up.
+	newPermissions := map[string]bool{}
+	for _, permission := range permissions {
+		_, err := stores.Permissions.FindByName(ctx, permission.Name)
+		if errors.Is(err, store.ErrNotFound) {
+
+			err := stores.Permissions.Create(ctx, &permission)
+			if err != nil {
+				log.Println("Error creating permission:", permission.Name)
+				continue
+			}
+			newPermissions[permission.Name] = true
+		}
+	}
+
+	createdPermissions, err := stores.Permissions.List(ctx)
+	if err != nil {
+		log.Fatal("Error fetching permissions:", err)
+	}
+
+	var adminPermissions []primitive.ObjectID
+	var userPermissions []primitive.ObjectID
+	var newAdminPermissions []primitive.ObjectID
+	var newUserPermissions []primitive.ObjectID
+
+	for _, permission := range createdPermissions {
+		adminPermissions = append(adminPermissions, permission.ID)
+		if newPermissions[permission.Name] {
+			newAdminPermissions = append(newAdminPermissions, permission.ID)
+		}
+		if userDefaults[permission.Name] {
+			userPermissions = append(userPermissions, permission.ID)
+			if newPermissions[permission.Name] {
+				newUserPermissions = append(newUserPermissions, permission.ID)
+			}
+		}
+	}
+
+	for _, role := range roles {
+		var permissionsToAssign, permissionsToAdd []primitive.ObjectID
+		if role.Name == models.RoleAdmin {
+			permissionsToAssign, permissionsToAdd = adminPermissions, newAdminPermissions
+		} else {
+			permissionsToAssign, permissionsToAdd = userPermissions, newUserPermissions
+		}
+
+		existingRole, err := stores.Roles.FindByName(ctx, role.Name)
+		if errors.Is(err, store.ErrNotFound) {
+
+			role.Permissions = permissionsToAssign
+			err := stores.Roles.Create(ctx, &role)
+			if err != nil {
+				log.Println("Error creating role:", role.Name)
+			}
+		} else if err == nil && len(permissionsToAdd) > 0 {
+			_, err := stores.Roles.AddPermissions(ctx, existingRole.ID, permissionsToAdd...)
+			if err != nil {
+				log.Println("Error granting new permissions to role:", role.Name)
+			}
+		}
+	}
+
+	migrateLegacyPermissions(ctx, stores)
+}
+
+// legacyPermissions maps permissions that are no longer seeded to the ones
+// replacing them. view_task let its holders see every task.
+var legacyPermissions = map[string]string{"view_task": "view_all_task"}
+
+// migrateLegacyPermissions grants the replacement of each legacy permission
+// to the roles holding it, detaches it and deletes it. The built-in user
+// role is seeded with narrower permissions instead, so it only loses the
+// legacy one.
+func migrateLegacyPermissions(ctx context.Context, stores *store.Stores) {
+	for legacyName, replacementName := range legacyPermissions {
+		legacy, err := stores.Permissions.FindByName(ctx, legacyName)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Fatal("Error fetching permission:", err)
+		}
+		replacement, err := stores.Permissions.FindByName(ctx, replacementName)
+		if err != nil {
+			log.Fatal("Error fetching permission:", err)
+		}
+
+		roles, err := stores.Roles.List(ctx)
+		if err != nil {
+			log.Fatal("Error fetching roles:", err)
+		}
+		for _, role := range roles {
+			if !slices.Contains(role.Permissions, legacy.ID) {
+				continue
+			}
+			if role.Name != models.RoleUser {
+				if _, err := stores.Roles.AddPermissions(ctx, role.ID, replacement.ID); err != nil {
+					log.Fatal("Error granting permission to role:", role.Name)
+				}
+			}
+			if _, err := stores.Roles.RemovePermission(ctx, role.ID, legacy.ID); err != nil {
+				log.Fatal("Error removing permission from role:", role.Name)
+			}
+		}
+
+		if err := stores.Permissions.Delete(ctx, legacy.ID); err != nil {
+			log.Fatal("Error deleting permission:", legacyName)
+		}
+		log.Printf("Replaced permission %s with %s", legacyName, replacementName)
+	}
+}
+
+// defaultProjectName names the project that adopts the tasks created
+// before projects existed.
+const defaultProjectName = "Default"
+
+// MigrateProjects moves a deployment that predates projects onto them. The
+// first time it runs against existing users it creates a default project,
+// makes every user a member with their global role and moves every task
+// into it. It does nothing once a project exists.
+func MigrateProjects(stores *store.Stores) {
+	ctx := context.Background()
+
+	projects, err := stores.Projects.Count(ctx)
+	if err != nil {
+		log.Fatal("Error counting projects:", err)
+	}
+	users, err := stores.Users.Count(ctx)
+	if err != nil {
+		log.Fatal("Error counting users:", err)
+	}
+	if projects > 0 || users == 0 {
+		return
+	}
+
+	now := time.Now()
+	project := models.Project{Name: defaultProjectName, Description: "Tasks created before projects were introduced", CreatedAt: now}
+	if err := stores.Projects.Create(ctx, &project); err != nil {
+		log.Fatal("Error creating default project:", err)
+	}
+
+	const pageSize = 100
+	for skip := 0; ; skip += pageSize {
+		page, _, err := stores.Users.List(ctx, store.UserFilter{}, skip, pageSize)
+		if err != nil {
+			log.Fatal("Error listing users:", err)
+		}
+		for _, user := range page {
+			member := models.ProjectMember{ProjectID: project.ID, UserID: user.ID, RoleID: user.RoleID, CreatedAt: now}
+			if err := stores.Members.Add(ctx, &member); err != nil && !errors.Is(err, store.ErrDuplicate) {
+				log.Println("Error adding user to default project:", user.ID.Hex())
+			}
+		}
+		if len(page) < pageSize {
+			break
+		}
+	}
+
+	moved, err := stores.Tasks.AdoptOrphans(ctx, project.ID)
+	if err != nil {
+		log.Fatal("Error moving tasks into the default project:", err)
+	}
+	log.Printf("Created project %q with %d users and %d tasks", project.Name, users, moved)
+}
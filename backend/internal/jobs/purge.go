@@ -0,0 +1,120 @@
+// Package jobs runs background maintenance work alongside the HTTP server.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/storage"
+	"backend/internal/store"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RunTaskPurge permanently removes tasks that have been in the trash for
+// longer than the configured retention, together with their history,
+// comments and attachments. It runs once immediately and then every
+// PurgeInterval until ctx is done.
+func RunTaskPurge(ctx context.Context, stores *store.Stores, files storage.Storage, cfg config.Tasks) {
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := PurgeTasks(ctx, stores, files, time.Now().Add(-cfg.TrashRetention)); err != nil {
+			log.Printf("Failed to purge deleted tasks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeTasks removes tasks deleted before the cutoff, then the history,
+// comments and attachments of every task that no longer exists. Sweeping by
+// existence rather than by the tasks just purged lets a run finish the
+// cleanup of an earlier one that failed part way.
+func PurgeTasks(ctx context.Context, stores *store.Stores, files storage.Storage, deletedBefore time.Time) error {
+	ids, purgeErr := stores.Tasks.Purge(ctx, deletedBefore)
+	if len(ids) > 0 {
+		log.Printf("Purged %d deleted tasks", len(ids))
+	}
+	if err := deleteOrphans(ctx, stores, files); err != nil {
+		return err
+	}
+	return purgeErr
+}
+
+// deleteOrphans removes the history, comments and attachments of tasks that
+// no longer exist. Attachment content goes first, so that a failure leaves
+// the metadata in place for the next run to retry.
+func deleteOrphans(ctx context.Context, stores *store.Stores, files storage.Storage) error {
+	orphans, err := orphanedTaskIDs(ctx, stores)
+	if err != nil || len(orphans) == 0 {
+		return err
+	}
+
+	for _, taskID := range orphans {
+		attachments, err := stores.Attachments.List(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		for _, attachment := range attachments {
+			if err := files.Delete(ctx, attachment.StorageKey); err != nil {
+				return fmt.Errorf("deleting attachment %s: %w", attachment.ID.Hex(), err)
+			}
+		}
+	}
+	if _, err := stores.Attachments.DeleteForTasks(ctx, orphans); err != nil {
+		return err
+	}
+	if err := stores.Comments.DeleteForTasks(ctx, orphans); err != nil {
+		return err
+	}
+	return stores.TaskHistory.DeleteForTasks(ctx, orphans)
+}
+
+// orphanedTaskIDs returns the IDs of tasks that have history, comments or
+// attachments but no longer exist.
+func orphanedTaskIDs(ctx context.Context, stores *store.Stores) ([]primitive.ObjectID, error) {
+	referenced := map[primitive.ObjectID]bool{}
+	for _, taskIDs := range []func(context.Context) ([]primitive.ObjectID, error){
+		stores.TaskHistory.TaskIDs,
+		stores.Comments.TaskIDs,
+		stores.Attachments.TaskIDs,
+	} {
+		ids, err := taskIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			referenced[id] = true
+		}
+	}
+	if len(referenced) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(referenced))
+	for id := range referenced {
+		ids = append(ids, id)
+	}
+	existing, err := stores.Tasks.FindExisting(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range existing {
+		delete(referenced, id)
+	}
+
+	orphans := make([]primitive.ObjectID, 0, len(referenced))
+	for id := range referenced {
+		orphans = append(orphans, id)
+	}
+	return orphans, nil
+}
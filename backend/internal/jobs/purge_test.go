@@ -0,0 +1,174 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/storage"
+	"backend/internal/store"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// trashedTask creates a task with history, a comment and an attachment and
+// moves it to the trash at deletedAt.
+func trashedTask(t *testing.T, stores *store.Stores, files storage.Storage, deletedAt time.Time) (models.Task, models.Attachment) {
+	t.Helper()
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+
+	task := models.Task{ProjectID: primitive.NewObjectID(), Name: "Task", Status: "todo", CreatedBy: userID, CreatedAt: deletedAt, Version: 1}
+	if err := stores.Tasks.Create(ctx, &task); err != nil {
+		t.Fatal(err)
+	}
+	if err := stores.TaskHistory.Append(ctx, &models.TaskVersion{TaskID: task.ID, Version: 1, Task: task, ChangedBy: userID, CreatedAt: deletedAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stores.Comments.Create(ctx, &models.Comment{TaskID: task.ID, AuthorID: userID, Body: "Comment", CreatedAt: deletedAt}); err != nil {
+		t.Fatal(err)
+	}
+	attachment := models.Attachment{TaskID: task.ID, Filename: "notes.txt", StorageKey: task.ID.Hex() + "-notes", UploadedBy: userID, CreatedAt: deletedAt}
+	if err := files.Put(ctx, attachment.StorageKey, strings.NewReader("notes")); err != nil {
+		t.Fatal(err)
+	}
+	if err := stores.Attachments.Create(ctx, &attachment); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stores.Tasks.Delete(ctx, task.ID, store.TaskScope{}, userID, deletedAt); err != nil {
+		t.Fatal(err)
+	}
+	return task, attachment
+}
+
+func TestPurgeTasksRemovesExpiredTasks(t *testing.T) {
+	ctx := context.Background()
+	stores := store.NewMemoryStores()
+	files := storage.NewMemory()
+	now := time.Now()
+
+	expired, expiredAttachment := trashedTask(t, stores, files, now.Add(-2*time.Hour))
+	recent, recentAttachment := trashedTask(t, stores, files, now.Add(-time.Minute))
+
+	if err := PurgeTasks(ctx, stores, files, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := stores.Tasks.FindByID(ctx, expired.ID, store.TaskScope{Trash: true}); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expired task lookup = %v, want ErrNotFound", err)
+	}
+	if versions, _ := stores.TaskHistory.List(ctx, expired.ID); len(versions) != 0 {
+		t.Errorf("expired task kept %d versions", len(versions))
+	}
+	if comments, _ := stores.Comments.List(ctx, expired.ID); len(comments) != 0 {
+		t.Errorf("expired task kept %d comments", len(comments))
+	}
+	if attachments, _ := stores.Attachments.List(ctx, expired.ID); len(attachments) != 0 {
+		t.Errorf("expired task kept %d attachments", len(attachments))
+	}
+	if _, err := files.Open(ctx, expiredAttachment.StorageKey); err == nil {
+		t.Error("expired task's attachment content was not deleted")
+	}
+
+	if _, err := stores.Tasks.FindByID(ctx, recent.ID, store.TaskScope{Trash: true}); err != nil {
+		t.Errorf("recent task lookup = %v, want it kept", err)
+	}
+	assertIntact(t, stores, files, recent, recentAttachment)
+}
+
+func TestPurgeTasksKeepsRestoredTasks(t *testing.T) {
+	ctx := context.Background()
+	stores := store.NewMemoryStores()
+	files := storage.NewMemory()
+	now := time.Now()
+
+	task, attachment := trashedTask(t, stores, files, now.Add(-2*time.Hour))
+	if _, err := stores.Tasks.Restore(ctx, task.ID, store.TaskScope{}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := PurgeTasks(ctx, stores, files, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := stores.Tasks.FindByID(ctx, task.ID, store.TaskScope{}); err != nil {
+		t.Errorf("restored task lookup = %v, want it kept", err)
+	}
+	assertIntact(t, stores, files, task, attachment)
+}
+
+// failingComments fails the next DeleteForTasks call while fail is set.
+type failingComments struct {
+	store.CommentStore
+	fail bool
+}
+
+func (s *failingComments) DeleteForTasks(ctx context.Context, taskIDs []primitive.ObjectID) error {
+	if s.fail {
+		s.fail = false
+		return errors.New("comments unavailable")
+	}
+	return s.CommentStore.DeleteForTasks(ctx, taskIDs)
+}
+
+func TestPurgeTasksRetriesFailedCleanup(t *testing.T) {
+	ctx := context.Background()
+	stores := store.NewMemoryStores()
+	files := storage.NewMemory()
+	now := time.Now()
+
+	task, attachment := trashedTask(t, stores, files, now.Add(-2*time.Hour))
+	stores.Comments = &failingComments{CommentStore: stores.Comments, fail: true}
+
+	if err := PurgeTasks(ctx, stores, files, now.Add(-time.Hour)); err == nil {
+		t.Fatal("first run succeeded, want the comment store's error")
+	}
+	if _, err := stores.Tasks.FindByID(ctx, task.ID, store.TaskScope{Trash: true}); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("task lookup after first run = %v, want ErrNotFound", err)
+	}
+	if comments, _ := stores.Comments.List(ctx, task.ID); len(comments) != 1 {
+		t.Fatalf("comments after first run = %d, want the failed deletion to leave 1", len(comments))
+	}
+
+	if err := PurgeTasks(ctx, stores, files, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if versions, _ := stores.TaskHistory.List(ctx, task.ID); len(versions) != 0 {
+		t.Errorf("second run kept %d versions", len(versions))
+	}
+	if comments, _ := stores.Comments.List(ctx, task.ID); len(comments) != 0 {
+		t.Errorf("second run kept %d comments", len(comments))
+	}
+	if attachments, _ := stores.Attachments.List(ctx, task.ID); len(attachments) != 0 {
+		t.Errorf("second run kept %d attachments", len(attachments))
+	}
+	if _, err := files.Open(ctx, attachment.StorageKey); err == nil {
+		t.Error("attachment content was not deleted")
+	}
+}
+
+// assertIntact checks that the task kept its history, comment and
+// attachment.
+func assertIntact(t *testing.T, stores *store.Stores, files storage.Storage, task models.Task, attachment models.Attachment) {
+	t.Helper()
+	ctx := context.Background()
+
+	if versions, err := stores.TaskHistory.List(ctx, task.ID); err != nil || len(versions) != 1 {
+		t.Errorf("versions = %d, %v; want 1", len(versions), err)
+	}
+	if comments, err := stores.Comments.List(ctx, task.ID); err != nil || len(comments) != 1 {
+		t.Errorf("comments = %d, %v; want 1", len(comments), err)
+	}
+	if attachments, err := stores.Attachments.List(ctx, task.ID); err != nil || len(attachments) != 1 {
+		t.Errorf("attachments = %d, %v; want 1", len(attachments), err)
+	}
+	content, err := files.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		t.Errorf("attachment content: %v", err)
+		return
+	}
+	content.Close()
+}
@@ -0,0 +1,59 @@
+package mail
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+)
+
+// File appends every message to a file instead of sending it. It is meant
+// for local development.
+type File struct {
+	mu   sync.Mutex
+	path string
+	from string
+}
+
+func NewFile(path, from string) *File {
+	return &File{path: path, from: from}
+}
+
+func (m *File) Send(ctx context.Context, msg Message) error {
+	raw, err := format(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(raw, "\r\n\r\n"...)); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Log writes every message to the standard logger instead of sending it.
+// It is meant for local development.
+type Log struct {
+	from string
+}
+
+func NewLog(from string) *Log {
+	return &Log{from: from}
+}
+
+func (m *Log) Send(ctx context.Context, msg Message) error {
+	raw, err := format(m.from, msg)
+	if err != nil {
+		return err
+	}
+	log.Printf("Email not sent (no SMTP server configured):\n%s", raw)
+	return nil
+}
@@ -0,0 +1,61 @@
+// Package mail sends the emails the application needs, such as account
+// verification and password reset links.
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+)
+
+// Message is a plain-text email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer described by cfg: SMTP when a host is configured,
+// otherwise a stand-in for local development that appends messages to a
+// file or writes them to the log.
+func New(cfg config.Mail) Mailer {
+	switch {
+	case cfg.SMTPHost != "":
+		return NewSMTP(cfg)
+	case cfg.File != "":
+		return NewFile(cfg.File, cfg.From)
+	default:
+		return NewLog(cfg.From)
+	}
+}
+
+var errHeaderInjection = errors.New("mail: header values must not contain line breaks")
+
+// format renders msg as an RFC 5322 message.
+func format(from string, msg Message) ([]byte, error) {
+	for _, value := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, errHeaderInjection
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String()), nil
+}
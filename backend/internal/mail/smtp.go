@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"strconv"
+
+	"backend/internal/config"
+)
+
+// SMTP sends messages through an SMTP server. Authentication is used when a
+// username is configured; net/smtp only sends credentials over TLS or to
+// localhost.
+type SMTP struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTP(cfg config.Mail) *SMTP {
+	mailer := &SMTP{
+		addr: net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		from: cfg.From,
+	}
+	if cfg.SMTPUsername != "" {
+		mailer.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return mailer
+}
+
+func (m *SMTP) Send(ctx context.Context, msg Message) error {
+	raw, err := format(m.from, msg)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, raw)
+}
@@ -0,0 +1,339 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const principalKey = "principal"
+
+// Principal is the authenticated caller together with its role and the
+// names of every permission granted by that role.
+type Principal struct {
+	User        models.User
+	Role        models.Role
+	Permissions map[string]bool
+	// MFAEnrollment is set for sessions that may only be used to set up
+	// two-factor authentication.
+	MFAEnrollment bool
+	// APIToken is set when the caller authenticated with a personal API
+	// token. Permissions then only include the token's scopes.
+	APIToken *models.APIToken
+}
+
+// Can reports whether the principal holds the named permission.
+func (p *Principal) Can(permission string) bool {
+	return p != nil && p.Permissions[permission]
+}
+
+// scopes returns the permissions the principal's API token is limited to,
+// or nil when the principal is not limited.
+func (p *Principal) scopes() map[string]bool {
+	if p == nil || p.APIToken == nil {
+		return nil
+	}
+	scopes := make(map[string]bool, len(p.APIToken.Scopes))
+	for _, scope := range p.APIToken.Scopes {
+		scopes[scope] = true
+	}
+	return scopes
+}
+
+// apiTokenTouchInterval limits how often an API token's last use is
+// written back.
+const apiTokenTouchInterval = time.Minute
+
+// HashToken returns the digest under which a secret token is stored: API,
+// refresh and account tokens as well as recovery codes.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Auth resolves callers from their JWT or personal API token and enforces
+// permissions.
+type Auth struct {
+	stores *store.Stores
+	secret []byte
+}
+
+func NewAuth(stores *store.Stores, cfg config.Auth) *Auth {
+	return &Auth{stores: stores, secret: []byte(cfg.JWTSecret)}
+}
+
+// ParseJWT verifies the token signature and expiry and rejects tokens whose
+// jti has been revoked.
+func (a *Auth) ParseJWT(ctx context.Context, token string) (*models.CustomClaims, error) {
+	claims := &models.CustomClaims{}
+	parsedToken, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return a.secret, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !parsedToken.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	revoked, err := a.stores.Tokens.IsAccessTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errors.New("token has been revoked")
+	}
+
+	return claims, nil
+}
+
+// CurrentPrincipal returns the principal attached by Authenticate, or nil
+// when the request has not been authenticated.
+func CurrentPrincipal(c *fiber.Ctx) *Principal {
+	principal, _ := c.Locals(principalKey).(*Principal)
+	return principal
+}
+
+// Authenticate resolves the caller from the token found by ExtractToken and
+// attaches it to the request. Cookie-authenticated requests that change
+// state must also pass the CSRF check. It is a no-op when an earlier
+// handler already did so.
+func (a *Auth) Authenticate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, err := a.resolvePrincipal(c); err != nil {
+			return respondError(c, err)
+		}
+		return c.Next()
+	}
+}
+
+// AuthenticateMFAEnrollment is like Authenticate but also admits sessions
+// limited to setting up two-factor authentication. It guards the endpoints
+// such a session needs.
+func (a *Auth) AuthenticateMFAEnrollment() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, err := a.loadPrincipal(c); err != nil {
+			return respondError(c, err)
+		}
+		return c.Next()
+	}
+}
+
+// RequireSession authenticates the caller and rejects personal API tokens,
+// so that a leaked token cannot be used to manage the account's credentials.
+func (a *Auth) RequireSession() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, err := a.loadPrincipal(c)
+		if err != nil {
+			return respondError(c, err)
+		}
+		if principal.APIToken != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "API tokens cannot be used for this action"})
+		}
+		return c.Next()
+	}
+}
+
+// RequirePermission authenticates the caller if needed and rejects the
+// request with 403 unless every named permission is held.
+func (a *Auth) RequirePermission(names ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, err := a.resolvePrincipal(c)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		for _, name := range names {
+			if !principal.Can(name) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You do not have permission to perform this action"})
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireAnyPermission is like RequirePermission but admits callers holding
+// at least one of the named permissions.
+func (a *Auth) RequireAnyPermission(names ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, err := a.resolvePrincipal(c)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		for _, name := range names {
+			if principal.Can(name) {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You do not have permission to perform this action"})
+	}
+}
+
+var errMFAEnrollmentRequired = fiber.NewError(fiber.StatusForbidden, "Two-factor authentication must be set up before continuing")
+
+func respondError(c *fiber.Ctx, err *fiber.Error) error {
+	switch err {
+	case errMFAEnrollmentRequired:
+		return c.Status(err.Code).JSON(fiber.Map{"error": err.Message, "code": "mfa_enrollment_required"})
+	case errCSRFTokenInvalid:
+		return c.Status(err.Code).JSON(fiber.Map{"error": err.Message, "code": "csrf_token_invalid"})
+	}
+	return c.Status(err.Code).JSON(fiber.Map{"error": err.Message})
+}
+
+// resolvePrincipal is loadPrincipal for ordinary endpoints, which sessions
+// limited to setting up two-factor authentication may not use.
+func (a *Auth) resolvePrincipal(c *fiber.Ctx) (*Principal, *fiber.Error) {
+	principal, err := a.loadPrincipal(c)
+	if err != nil {
+		return nil, err
+	}
+	if principal.MFAEnrollment {
+		return nil, errMFAEnrollmentRequired
+	}
+	return principal, nil
+}
+
+func (a *Auth) loadPrincipal(c *fiber.Ctx) (*Principal, *fiber.Error) {
+	if principal := CurrentPrincipal(c); principal != nil {
+		return principal, nil
+	}
+
+	token, source := ExtractToken(c)
+	if source == TokenNone {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Authorization token is missing")
+	}
+	if source == TokenCookie && !ValidCSRF(c) {
+		return nil, errCSRFTokenInvalid
+	}
+	if strings.HasPrefix(token, models.APITokenPrefix) {
+		return a.loadAPITokenPrincipal(c, token)
+	}
+
+	ctx := c.UserContext()
+	claims, err := a.ParseJWT(ctx, token)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired token")
+	}
+
+	userID, err := primitive.ObjectIDFromHex(claims.Issuer)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid user ID in token")
+	}
+
+	principal, ferr := a.newPrincipal(ctx, userID)
+	if ferr != nil {
+		return nil, ferr
+	}
+	if validAfter := principal.User.SessionsValidAfter; validAfter != nil && (claims.IssuedAt == nil || claims.IssuedAt.Before(*validAfter)) {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired token")
+	}
+	principal.MFAEnrollment = claims.MFAEnrollment
+
+	c.Locals(principalKey, principal)
+	return principal, nil
+}
+
+// loadAPITokenPrincipal authenticates a personal API token. The principal
+// keeps only the permissions that are both granted by the owner's role and
+// listed in the token's scopes.
+func (a *Auth) loadAPITokenPrincipal(c *fiber.Ctx, token string) (*Principal, *fiber.Error) {
+	ctx := c.UserContext()
+	now := time.Now()
+	apiToken, err := a.stores.APITokens.FindByHash(ctx, HashToken(token), now)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired token")
+	}
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to verify token")
+	}
+
+	principal, ferr := a.newPrincipal(ctx, apiToken.UserID)
+	if ferr != nil {
+		return nil, ferr
+	}
+	principal.APIToken = apiToken
+	principal.Permissions = intersect(principal.Permissions, principal.scopes())
+
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) >= apiTokenTouchInterval {
+		if err := a.stores.APITokens.Touch(ctx, apiToken.ID, now); err != nil {
+			log.Printf("Failed to record API token use: %v", err)
+		}
+	}
+
+	c.Locals(principalKey, principal)
+	return principal, nil
+}
+
+// newPrincipal loads an enabled user together with their global role and
+// its permissions.
+func (a *Auth) newPrincipal(ctx context.Context, userID primitive.ObjectID) (*Principal, *fiber.Error) {
+	user, err := a.stores.Users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+	if user.Disabled {
+		return nil, fiber.NewError(fiber.StatusForbidden, "Account is disabled")
+	}
+
+	role, err := a.stores.Roles.FindByID(ctx, user.RoleID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Role not found")
+	}
+
+	permissions, err := a.permissionNames(ctx, role)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve permissions")
+	}
+
+	return &Principal{User: *user, Role: *role, Permissions: permissions}, nil
+}
+
+// intersect returns the permissions that are also in scopes. A nil scopes
+// leaves permissions unchanged.
+func intersect(permissions, scopes map[string]bool) map[string]bool {
+	if scopes == nil {
+		return permissions
+	}
+	kept := make(map[string]bool, len(scopes))
+	for name := range permissions {
+		if scopes[name] {
+			kept[name] = true
+		}
+	}
+	return kept
+}
+
+// permissionNames returns the set of permission names granted by role.
+func (a *Auth) permissionNames(ctx context.Context, role *models.Role) (map[string]bool, error) {
+	permissions, err := a.stores.Permissions.FindByIDs(ctx, role.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		names[permission.Name] = true
+	}
+	return names, nil
+}
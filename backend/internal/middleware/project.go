@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"errors"
+
+	"backend/internal/models"
+	"backend/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const projectKey = "project"
+
+// AdministerProjects is the global permission that grants full access to
+// every project, whether or not the holder is a member.
+const AdministerProjects = "administer_projects"
+
+// ProjectAccess is the project named in the request path together with the
+// caller's membership and the permissions their project role grants.
+type ProjectAccess struct {
+	Project models.Project
+	// Member is nil when the caller reaches the project only through
+	// administer_projects.
+	Member      *models.ProjectMember
+	Permissions map[string]bool
+	// Override is set for holders of administer_projects, who may do
+	// anything within the project.
+	Override bool
+	// Scopes limits every permission, including Override, when the caller
+	// uses a personal API token. It is nil otherwise.
+	Scopes map[string]bool
+}
+
+// Can reports whether the caller holds the named permission in the project.
+func (p *ProjectAccess) Can(permission string) bool {
+	if p == nil || (p.Scopes != nil && !p.Scopes[permission]) {
+		return false
+	}
+	return p.Override || p.Permissions[permission]
+}
+
+// CurrentProject returns the project attached by one of the project
+// middlewares, or nil when the route is not project-scoped.
+func CurrentProject(c *fiber.Ctx) *ProjectAccess {
+	project, _ := c.Locals(projectKey).(*ProjectAccess)
+	return project
+}
+
+// RequireProjectMember admits members of the :pid project and holders of
+// administer_projects. Everyone else gets 404 so that projects they cannot
+// see stay hidden.
+func (a *Auth) RequireProjectMember() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, err := a.resolveProject(c); err != nil {
+			return respondError(c, err)
+		}
+		return c.Next()
+	}
+}
+
+// RequireProjectPermission is like RequirePermission but checks the role
+// the caller holds in the :pid project.
+func (a *Auth) RequireProjectPermission(names ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		project, err := a.resolveProject(c)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		for _, name := range names {
+			if !project.Can(name) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You do not have permission to perform this action"})
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireAnyProjectPermission is like RequireProjectPermission but admits
+// callers holding at least one of the named permissions in the project.
+func (a *Auth) RequireAnyProjectPermission(names ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		project, err := a.resolveProject(c)
+		if err != nil {
+			return respondError(c, err)
+		}
+
+		for _, name := range names {
+			if project.Can(name) {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You do not have permission to perform this action"})
+	}
+}
+
+func (a *Auth) resolveProject(c *fiber.Ctx) (*ProjectAccess, *fiber.Error) {
+	if project := CurrentProject(c); project != nil {
+		return project, nil
+	}
+
+	principal, ferr := a.resolvePrincipal(c)
+	if ferr != nil {
+		return nil, ferr
+	}
+
+	projectID, err := primitive.ObjectIDFromHex(c.Params("pid"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid project ID")
+	}
+
+	ctx := c.UserContext()
+	project, err := a.stores.Projects.FindByID(ctx, projectID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Project not found")
+	}
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve project")
+	}
+
+	access := &ProjectAccess{
+		Project:     *project,
+		Permissions: map[string]bool{},
+		Override:    principal.Can(AdministerProjects),
+		Scopes:      principal.scopes(),
+	}
+
+	member, err := a.stores.Members.Find(ctx, projectID, principal.User.ID)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		if !access.Override {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Project not found")
+		}
+	case err != nil:
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve project membership")
+	default:
+		access.Member = member
+
+		role, err := a.stores.Roles.FindByID(ctx, member.RoleID)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Role not found")
+		}
+		permissions, err := a.permissionNames(ctx, role)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve permissions")
+		}
+		access.Permissions = intersect(permissions, access.Scopes)
+	}
+
+	c.Locals(projectKey, access)
+	return access, nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APITokenPrefix starts every personal API token, so that they can be told
+// apart from session JWTs and recognised by secret scanners.
+const APITokenPrefix = "tmpat_"
+
+// APIToken is a personal access token a user creates for scripts and CI.
+// It acts as its owner but only with the permissions listed in Scopes.
+// Only a hash of the token is stored.
+type APIToken struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name      string             `json:"name" bson:"name" validate:"required,max=100"`
+	TokenHash string             `json:"-" bson:"token_hash"`
+	// Hint is the start of the token, shown so that users can tell their
+	// tokens apart.
+	Hint       string     `json:"hint" bson:"hint"`
+	Scopes     []string   `json:"scopes" bson:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at" bson:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Attachment describes a file uploaded to a task. The content is kept by
+// the storage backend under StorageKey.
+type Attachment struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	TaskID      primitive.ObjectID `json:"task_id" bson:"task_id"`
+	Filename    string             `json:"filename" bson:"filename"`
+	ContentType string             `json:"content_type" bson:"content_type"`
+	Size        int64              `json:"size" bson:"size"`
+	StorageKey  string             `json:"-" bson:"storage_key"`
+	UploadedBy  primitive.ObjectID `json:"uploaded_by" bson:"uploaded_by"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
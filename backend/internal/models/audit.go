@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditEntry records a single security-relevant or task action. Entries are
+// append-only and never modified once written.
+type AuditEntry struct {
+	ID primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	// ActorID is the user who performed the action. It is nil when the
+	// caller could not be identified, for example a failed login.
+	ActorID    *primitive.ObjectID    `json:"actor_id" bson:"actor_id"`
+	Action     string                 `json:"action" bson:"action"`
+	TargetType string                 `json:"target_type,omitempty" bson:"target_type,omitempty"`
+	TargetID   *primitive.ObjectID    `json:"target_id,omitempty" bson:"target_id,omitempty"`
+	Changes    map[string]FieldChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	IP         string                 `json:"ip" bson:"ip"`
+	CreatedAt  time.Time              `json:"created_at" bson:"created_at"`
+}
+
+// FieldChange is the value of a field before and after an action. Values
+// use their JSON representation; a nil value means the field was absent.
+type FieldChange struct {
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Comment is a message posted on a task.
+type Comment struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	TaskID    primitive.ObjectID `json:"task_id" bson:"task_id"`
+	AuthorID  primitive.ObjectID `json:"author_id" bson:"author_id"`
+	Body      string             `json:"body" bson:"body" validate:"required,max=5000"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	// EditedAt is set once the author changes the body.
+	EditedAt *time.Time `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of change recorded in a task's history.
+const (
+	TaskChangeCreate  = "create"
+	TaskChangeUpdate  = "update"
+	TaskChangeRevert  = "revert"
+	TaskChangeDelete  = "delete"
+	TaskChangeRestore = "restore"
+)
+
+// TaskVersion is a snapshot of a task taken after a change.
+type TaskVersion struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	TaskID    primitive.ObjectID `json:"task_id" bson:"task_id"`
+	Version   int                `json:"version" bson:"version"`
+	Change    string             `json:"change" bson:"change"`
+	Task      Task               `json:"task" bson:"task"`
+	ChangedBy primitive.ObjectID `json:"changed_by" bson:"changed_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
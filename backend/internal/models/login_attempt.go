@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LoginAttempt counts the recent failed logins for one key, such as a
+// client IP address or an email address.
+type LoginAttempt struct {
+	Key         string    `json:"key" bson:"_id"`
+	Failures    int       `json:"failures" bson:"failures"`
+	LastFailure time.Time `json:"last_failure" bson:"last_failure"`
+	// LockedUntil is when the next attempt for this key is allowed.
+	LockedUntil time.Time `json:"locked_until" bson:"locked_until"`
+	ExpiresAt   time.Time `json:"-" bson:"expires_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MFA is a user's TOTP enrollment. It stays pending, and is ignored at
+// login, until a first code from the authenticator app has been verified.
+type MFA struct {
+	UserID  primitive.ObjectID `json:"user_id" bson:"_id"`
+	Secret  string             `json:"-" bson:"secret"`
+	Enabled bool               `json:"enabled" bson:"enabled"`
+	// RecoveryCodes holds hashes of the unused recovery codes.
+	RecoveryCodes []string `json:"-" bson:"recovery_codes"`
+	// LastStep is the time step of the last accepted code, so that a code
+	// cannot be used twice.
+	LastStep  int64      `json:"-" bson:"last_step"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	EnabledAt *time.Time `json:"enabled_at,omitempty" bson:"enabled_at,omitempty"`
+}
+
+// MFAPolicy decides which users must use two-factor authentication. When
+// enforced, it applies to everyone holding one of Permissions through their
+// global role or a project role.
+type MFAPolicy struct {
+	Enforced    bool                `json:"enforced" bson:"enforced"`
+	Permissions []string            `json:"permissions" bson:"permissions"`
+	UpdatedAt   *time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+	UpdatedBy   *primitive.ObjectID `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+}
+
+// DefaultMFAPolicy is in effect until an administrator saves one: not
+// enforced, and covering task deletion and role management once it is.
+func DefaultMFAPolicy() MFAPolicy {
+	return MFAPolicy{Permissions: []string{"delete_task", "manage_roles"}}
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Project groups tasks. Access to a project's tasks is governed by the role
+// each member holds in that project.
+type Project struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name" validate:"required,max=100"`
+	Description string             `json:"description" bson:"description" validate:"max=2000"`
+	CreatedBy   primitive.ObjectID `json:"created_by" bson:"created_by,omitempty"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// ProjectMember grants a user a role within one project. The role's
+// permissions apply to that project only.
+type ProjectMember struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	ProjectID primitive.ObjectID `json:"project_id" bson:"project_id"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	RoleID    primitive.ObjectID `json:"role_id" bson:"role_id"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshToken is a single link in a rotating refresh-token family. Only a
+// hash of the token is stored. A token that is presented after it has been
+// used marks the whole family as compromised.
+type RefreshToken struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	FamilyID  primitive.ObjectID `json:"family_id" bson:"family_id"`
+	TokenHash string             `json:"-" bson:"token_hash"`
+	Used      bool               `json:"used" bson:"used"`
+	Revoked   bool               `json:"revoked" bson:"revoked"`
+	ExpiresAt time.Time          `json:"expires_at" bson:"expires_at"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// RevokedToken is a denylist entry for an access token, keyed by its jti.
+type RevokedToken struct {
+	JTI       string    `json:"jti" bson:"_id"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// Purposes of an AccountToken.
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+	// TokenPurposeMFAChallenge is returned by a password login on an account
+	// with two-factor authentication and exchanged for a session together
+	// with a code.
+	TokenPurposeMFAChallenge = "mfa_challenge"
+)
+
+// AccountToken is a single-use token mailed to a user to verify their email
+// address or reset their password, or handed out to complete a two-factor
+// login. Only a hash of the token is stored.
+type AccountToken struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Purpose   string             `json:"purpose" bson:"purpose"`
+	TokenHash string             `json:"-" bson:"token_hash"`
+	ExpiresAt time.Time          `json:"expires_at" bson:"expires_at"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
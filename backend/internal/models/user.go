@@ -13,16 +13,41 @@ type User struct {
 	Email    string             `json:"email" bson:"email"`
 	Password []byte             `json:"-" bson:"password"`
 	RoleID   primitive.ObjectID `json:"role_id" bson:"role_id"` 
+	Disabled bool               `json:"disabled" bson:"disabled"`
+	EmailVerified bool          `json:"email_verified" bson:"email_verified"`
+	// SessionsValidAfter rejects access tokens issued before it, so that a
+	// password reset ends sessions that were already signed in.
+	SessionsValidAfter *time.Time `json:"-" bson:"sessions_valid_after,omitempty"`
 }
 
 
+// Task priorities, from least to most pressing.
+const (
+    PriorityLow    = "low"
+    PriorityMedium = "medium"
+    PriorityHigh   = "high"
+    PriorityUrgent = "urgent"
+)
+
 type Task struct {
     ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-    Name      string             `json:"name" bson:"name"`
-    Description string           `json:"description" bson:"description"`
-    Status    bool             `json:"status" bson:"status"`
+    ProjectID primitive.ObjectID `json:"project_id" bson:"project_id"`
+    Name      string             `json:"name" bson:"name" validate:"required,max=200"`
+    Description string           `json:"description" bson:"description" validate:"max=5000"`
+    // Status is one of the statuses of the configured workflow.
+    Status    string             `json:"status" bson:"status"`
+    Priority  string             `json:"priority" bson:"priority" validate:"oneof=low medium high urgent"`
+    DueDate   *time.Time         `json:"due_date,omitempty" bson:"due_date,omitempty"`
+    Labels    []string           `json:"labels" bson:"labels,omitempty" validate:"max=20"`
+    CreatedBy primitive.ObjectID   `json:"created_by" bson:"created_by,omitempty"`
+    Assignees []primitive.ObjectID `json:"assignees" bson:"assignees,omitempty" validate:"max=50"`
     CreatedAt time.Time          `json:"created_at" bson:"created_at"`
     UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+    // Version starts at 1 and is incremented by every update.
+    Version   int                `json:"version" bson:"version"`
+    // DeletedAt and DeletedBy are set while the task is in the trash.
+    DeletedAt *time.Time          `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+    DeletedBy *primitive.ObjectID `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 }
 
 
@@ -39,8 +64,23 @@ type Role struct {
 	Permissions []primitive.ObjectID `json:"permissions" bson:"permissions"` 
 }
 
+// Names of the roles seeded at startup. Registration and project creation
+// look them up by name, so they cannot be renamed or deleted.
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// BuiltIn reports whether the role is one of the seeded roles.
+func (r Role) BuiltIn() bool {
+	return r.Name == RoleAdmin || r.Name == RoleUser
+}
+
 
 type CustomClaims struct {
     Role   string             `json:"role"`
+    // MFAEnrollment limits the session to setting up two-factor
+    // authentication, for users the MFA policy requires it of.
+    MFAEnrollment bool `json:"mfa_enrollment,omitempty"`
     jwt.RegisteredClaims
 }
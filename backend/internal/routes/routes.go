@@ -1,19 +1,109 @@
 package routes
 
 import (
+	"backend/internal/audit"
+	"backend/internal/config"
 	"backend/internal/controllers"
+	"backend/internal/mail"
+	"backend/internal/middleware"
+	"backend/internal/storage"
+	"backend/internal/store"
+	"backend/internal/throttle"
+
 	"github.com/gofiber/fiber/v2"
 )
 
+func Stepup(app *fiber.App, stores *store.Stores, files storage.Storage, mailer mail.Mailer, health *controllers.HealthController, cfg *config.Config) {
+	auth := middleware.NewAuth(stores, cfg.Auth)
+	auditLog := audit.NewLogger(stores.Audit)
+	limiter := throttle.NewLoginLimiter(stores.Logins, cfg.Login)
+	authController := controllers.NewAuthController(stores, auth, cfg.Auth, auditLog, mailer, cfg.Server.AppURL, limiter)
+	taskController := controllers.NewTaskController(stores, auditLog, cfg.Tasks.Workflow, files, cfg.Attachments)
+	projectController := controllers.NewProjectController(stores, auditLog)
+	roleController := controllers.NewRoleController(stores, auditLog)
+	userController := controllers.NewUserController(stores, auditLog, limiter)
+	mfaPolicyController := controllers.NewMFAPolicyController(stores, auditLog)
+	apiTokenController := controllers.NewAPITokenController(stores, auditLog)
+	auditController := controllers.NewAuditController(stores)
+
+	app.Get("/healthz", health.Live)
+	app.Get("/readyz", health.Ready)
+
+	app.Post("/api/register", authController.Register)
+	app.Post("/api/login", authController.Login)
+	app.Post("/api/login/mfa", authController.LoginMFA)
+	app.Get("/api/user", auth.AuthenticateMFAEnrollment(), authController.User)
+	app.Post("/api/refresh", authController.Refresh)
+	app.Post("/api/logout", authController.Logout)
+	app.Post("/api/verify-email", authController.VerifyEmail)
+	app.Post("/api/verify-email/resend", authController.ResendVerification)
+	app.Post("/api/forgot-password", authController.ForgotPassword)
+	app.Post("/api/reset-password", authController.ResetPassword)
+
+	// Sessions limited to setting up two-factor authentication may use
+	// these endpoints and nothing else. API tokens may not.
+	mfa := app.Group("/api/mfa", auth.AuthenticateMFAEnrollment(), auth.RequireSession())
+	mfa.Get("/", authController.GetMFA)
+	mfa.Post("/enroll", authController.EnrollMFA)
+	mfa.Post("/enable", authController.EnableMFA)
+	mfa.Post("/recovery-codes", authController.RegenerateRecoveryCodes)
+	mfa.Post("/disable", authController.DisableMFA)
+
+	tokens := app.Group("/api/tokens", auth.Authenticate(), auth.RequireSession())
+	tokens.Get("/", apiTokenController.ListTokens)
+	tokens.Post("/", apiTokenController.CreateToken)
+	tokens.Delete("/:id", apiTokenController.RevokeToken)
+
+	projects := app.Group("/api/projects")
+	projects.Get("/", auth.Authenticate(), projectController.ListProjects)
+	projects.Post("/", auth.RequirePermission("create_project"), projectController.CreateProject)
+	projects.Get("/:pid", auth.RequireProjectMember(), projectController.GetProject)
+	projects.Patch("/:pid", auth.RequireProjectPermission("manage_project"), projectController.UpdateProject)
+	projects.Get("/:pid/members", auth.RequireProjectMember(), projectController.ListMembers)
+	projects.Post("/:pid/members", auth.RequireProjectPermission("manage_project"), projectController.AddMember)
+	projects.Patch("/:pid/members/:userId", auth.RequireProjectPermission("manage_project"), projectController.UpdateMember)
+	projects.Delete("/:pid/members/:userId", auth.RequireProjectPermission("manage_project"), projectController.RemoveMember)
+
+	tasks := projects.Group("/:pid/tasks")
+	tasks.Post("/", auth.RequireProjectPermission("create_task"), taskController.CreateTask)
+	tasks.Get("/", auth.RequireAnyProjectPermission("view_own_task", "view_all_task"), taskController.GetTasks)
+	tasks.Get("/workflow", auth.RequireAnyProjectPermission("view_own_task", "view_all_task"), taskController.GetWorkflow)
+	tasks.Get("/trash", auth.RequireProjectPermission("delete_task"), taskController.GetTrash)
+	tasks.Get("/:id", auth.RequireAnyProjectPermission("view_own_task", "view_all_task"), taskController.GetTask)
+	tasks.Get("/:id/history", auth.RequireAnyProjectPermission("view_own_task", "view_all_task"), taskController.GetTaskHistory)
+	tasks.Get("/:id/activity", auth.RequireAnyProjectPermission("view_own_task", "view_all_task"), taskController.GetTaskActivity)
+	tasks.Get("/:id/comments", auth.RequireAnyProjectPermission("view_own_task", "view_all_task"), taskController.GetComments)
+	tasks.Post("/:id/comments", auth.RequireProjectPermission("comment_task"), taskController.CreateComment)
+	tasks.Patch("/:id/comments/:commentId", auth.RequireProjectPermission("comment_task"), taskController.UpdateComment)
+	tasks.Delete("/:id/comments/:commentId", auth.RequireAnyProjectPermission("comment_task", "moderate_comments"), taskController.DeleteComment)
+	tasks.Get("/:id/attachments", auth.RequireAnyProjectPermission("view_own_task", "view_all_task"), taskController.GetAttachments)
+	tasks.Post("/:id/attachments", auth.RequireProjectPermission("update_task"), taskController.UploadAttachment)
+	tasks.Get("/:id/attachments/:attachmentId", auth.RequireAnyProjectPermission("view_own_task", "view_all_task"), taskController.DownloadAttachment)
+	tasks.Delete("/:id/attachments/:attachmentId", auth.RequireProjectPermission("update_task"), taskController.DeleteAttachment)
+	tasks.Post("/:id/revert", auth.RequireProjectPermission("update_task"), taskController.RevertTask)
+	tasks.Put("/:id", auth.RequireProjectPermission("update_task"), taskController.UpdateTask)
+	tasks.Patch("/:id", auth.RequireProjectPermission("update_task"), taskController.PatchTask)
+	tasks.Delete("/:id", auth.RequireProjectPermission("delete_task"), taskController.DeleteTask)
+	tasks.Post("/:id/restore", auth.RequireProjectPermission("delete_task"), taskController.RestoreTask)
+
+	roles := app.Group("/api/roles", auth.RequirePermission("manage_roles"))
+	roles.Get("/", roleController.GetRoles)
+	roles.Post("/", roleController.CreateRole)
+	roles.Put("/:id", roleController.RenameRole)
+	roles.Delete("/:id", roleController.DeleteRole)
+	roles.Post("/:id/permissions/:permissionId", roleController.AttachPermission)
+	roles.Delete("/:id/permissions/:permissionId", roleController.DetachPermission)
+
+	app.Get("/api/permissions", auth.RequirePermission("manage_roles"), roleController.GetPermissions)
+
+	users := app.Group("/api/admin/users", auth.RequirePermission("manage_users"))
+	users.Get("/", userController.ListUsers)
+	users.Patch("/:id", userController.UpdateUser)
+	users.Post("/:id/unlock", userController.UnlockUser)
+	users.Delete("/:id/mfa", userController.ResetMFA)
 
-func Stepup(app *fiber.App){
-	app.Post("/api/register", controllers.Register)
-	app.Post("api/login",controllers.Login)
-	app.Get("api/user",controllers.User)
-	app.Post("api/logout",controllers.Logout)
+	app.Get("/api/admin/mfa-policy", auth.RequirePermission("manage_roles"), mfaPolicyController.GetPolicy)
+	app.Put("/api/admin/mfa-policy", auth.RequirePermission("manage_roles"), mfaPolicyController.UpdatePolicy)
 
-	app.Post("/api/tasks", controllers.CreateTask)    
-    app.Get("/api/tasks", controllers.GetTasks)      
-    app.Put("/api/tasks/:id", controllers.UpdateTask) 
-    app.Delete("/api/tasks/:id", controllers.DeleteTask)
-}
\ No newline at end of file
+	app.Get("/api/admin/audit", auth.RequirePermission("view_audit_log"), auditController.ListAuditEntries)
+}
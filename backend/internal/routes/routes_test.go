@@ -0,0 +1,271 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/controllers"
+	"backend/internal/initialize"
+	"backend/internal/mail"
+	"backend/internal/storage"
+	"backend/internal/store"
+	"backend/internal/throttle"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// discardMailer drops every email.
+type discardMailer struct{}
+
+func (discardMailer) Send(ctx context.Context, msg mail.Message) error { return nil }
+
+// testConfig is the configuration the test apps run with.
+func testConfig() config.Config {
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = "test-secret"
+	cfg.Auth.BcryptCost = bcrypt.MinCost
+	cfg.Auth.RequireVerifiedEmail = false
+	return cfg
+}
+
+// newTestApp serves the API from memory stores with seeded roles.
+func newTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+	return newTestAppWith(t, store.NewMemoryStores(), discardMailer{})
+}
+
+// newTestAppWith serves the API from stores, seeding them with the roles,
+// and sends emails through mailer.
+func newTestAppWith(t *testing.T, stores *store.Stores, mailer mail.Mailer) *fiber.App {
+	t.Helper()
+	initialize.InitializePermissionsAndRoles(stores)
+
+	cfg := testConfig()
+	app := fiber.New()
+	health := controllers.NewHealthController(func(context.Context) error { return nil })
+	Stepup(app, stores, storage.NewMemory(), mailer, health, &cfg)
+	return app
+}
+
+// call sends a JSON request, authenticated with bearer when it is set, and
+// decodes the JSON response.
+func call(t *testing.T, app *fiber.App, method, path, bearer, body string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, raw, err)
+		}
+	}
+	return resp.StatusCode, decoded
+}
+
+// mustCall is call for requests that must answer with want.
+func mustCall(t *testing.T, app *fiber.App, method, path, bearer, body string, want int) map[string]any {
+	t.Helper()
+	status, decoded := call(t, app, method, path, bearer, body)
+	if status != want {
+		t.Fatalf("%s %s = %d %v, want %d", method, path, status, decoded, want)
+	}
+	return decoded
+}
+
+// login registers a user and returns the login response.
+func login(t *testing.T, app *fiber.App, email string) map[string]any {
+	t.Helper()
+	mustCall(t, app, "POST", "/api/register", "", `{"name":"Test","email":"`+email+`","password":"password1"}`, http.StatusCreated)
+	return mustCall(t, app, "POST", "/api/login", "", `{"email":"`+email+`","password":"password1"}`, http.StatusOK)
+}
+
+// newTask creates a project and a task in it as the user holding token and
+// returns the project's task URL and the task.
+func newTask(t *testing.T, app *fiber.App, token string) (string, map[string]any) {
+	t.Helper()
+	created := mustCall(t, app, "POST", "/api/projects", token, `{"name":"Launch"}`, http.StatusCreated)
+	projectID, _ := created["project"].(map[string]any)["_id"].(string)
+	tasks := "/api/projects/" + projectID + "/tasks"
+
+	created = mustCall(t, app, "POST", tasks, token, `{"name":"Write docs","priority":"high"}`, http.StatusCreated)
+	task, _ := created["task"].(map[string]any)
+	return tasks, task
+}
+
+func toJSON(t *testing.T, v any) string {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
+
+func TestTaskTrashAndRestore(t *testing.T) {
+	app := newTestApp(t)
+	token, _ := login(t, app, "admin@example.com")["token"].(string)
+	tasks, task := newTask(t, app, token)
+	taskID, _ := task["_id"].(string)
+
+	mustCall(t, app, "DELETE", tasks+"/"+taskID, token, "", http.StatusOK)
+	mustCall(t, app, "GET", tasks+"/"+taskID, token, "", http.StatusNotFound)
+	trash := mustCall(t, app, "GET", tasks+"/trash", token, "", http.StatusOK)
+	if !strings.Contains(toJSON(t, trash), taskID) {
+		t.Errorf("trash = %v, want it to list %s", trash, taskID)
+	}
+
+	mustCall(t, app, "POST", tasks+"/"+taskID+"/restore", token, "", http.StatusOK)
+	mustCall(t, app, "GET", tasks+"/"+taskID, token, "", http.StatusOK)
+	trash = mustCall(t, app, "GET", tasks+"/trash", token, "", http.StatusOK)
+	if strings.Contains(toJSON(t, trash), taskID) {
+		t.Errorf("trash = %v, want %s restored", trash, taskID)
+	}
+}
+
+func TestTaskWorkflow(t *testing.T) {
+	app := newTestApp(t)
+	token, _ := login(t, app, "admin@example.com")["token"].(string)
+	tasks, task := newTask(t, app, token)
+	taskID, _ := task["_id"].(string)
+	if task["status"] != "todo" {
+		t.Errorf("new task status = %v, want todo", task["status"])
+	}
+
+	// The default workflow has no move from todo to review.
+	status, body := call(t, app, "PATCH", tasks+"/"+taskID, token, `{"status":"review"}`)
+	if status != http.StatusBadRequest || body["code"] != "validation_failed" {
+		t.Errorf("todo to review = %d %v, want a validation error", status, body)
+	}
+
+	updated := mustCall(t, app, "PATCH", tasks+"/"+taskID, token, `{"status":"in_progress"}`, http.StatusOK)
+	if task, _ := updated["task"].(map[string]any); task["status"] != "in_progress" {
+		t.Errorf("updated task = %v, want status in_progress", task)
+	}
+}
+
+func TestRefreshRotatesAndDetectsReuse(t *testing.T) {
+	app := newTestApp(t)
+	session := login(t, app, "admin@example.com")
+	first, _ := session["refresh_token"].(string)
+	if first == "" {
+		t.Fatalf("login returned no refresh token: %v", session)
+	}
+
+	rotated := mustCall(t, app, "POST", "/api/refresh", "", `{"refresh_token":"`+first+`"}`, http.StatusOK)
+	second, _ := rotated["refresh_token"].(string)
+	if second == "" || second == first {
+		t.Fatalf("refresh returned %q, want a new refresh token", second)
+	}
+	if token, _ := rotated["token"].(string); token == "" {
+		t.Fatalf("refresh returned no access token: %v", rotated)
+	}
+
+	// Reusing the first token suggests it was stolen, so the whole family
+	// is revoked, including the token that replaced it.
+	mustCall(t, app, "POST", "/api/refresh", "", `{"refresh_token":"`+first+`"}`, http.StatusUnauthorized)
+	mustCall(t, app, "POST", "/api/refresh", "", `{"refresh_token":"`+second+`"}`, http.StatusUnauthorized)
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	app := newTestApp(t)
+	token, _ := login(t, app, "admin@example.com")["token"].(string)
+
+	mustCall(t, app, "GET", "/api/user", token, "", http.StatusOK)
+	mustCall(t, app, "POST", "/api/logout", token, "", http.StatusOK)
+	mustCall(t, app, "GET", "/api/user", token, "", http.StatusUnauthorized)
+}
+
+func TestTasksHiddenFromNonMembers(t *testing.T) {
+	app := newTestApp(t)
+	admin, _ := login(t, app, "admin@example.com")["token"].(string)
+	other, _ := login(t, app, "user@example.com")["token"].(string)
+	tasks, task := newTask(t, app, admin)
+	taskID, _ := task["_id"].(string)
+
+	mustCall(t, app, "GET", tasks, other, "", http.StatusNotFound)
+	mustCall(t, app, "GET", tasks+"/"+taskID, other, "", http.StatusNotFound)
+}
+
+// captureMailer hands every email to a channel.
+type captureMailer chan mail.Message
+
+func (m captureMailer) Send(ctx context.Context, msg mail.Message) error {
+	m <- msg
+	return nil
+}
+
+// token returns the token in the link of the next email.
+func (m captureMailer) token(t *testing.T) string {
+	t.Helper()
+	select {
+	case msg := <-m:
+		_, token, found := strings.Cut(msg.Body, "token=")
+		if !found {
+			t.Fatalf("email %q has no token", msg.Subject)
+		}
+		return strings.Fields(token)[0]
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email was sent")
+		return ""
+	}
+}
+
+func TestResetPasswordEndsSessionsAndLiftsLockout(t *testing.T) {
+	ctx := context.Background()
+	stores := store.NewMemoryStores()
+	mailer := make(captureMailer, 10)
+	app := newTestAppWith(t, stores, mailer)
+
+	const email = "admin@example.com"
+	session := login(t, app, email)
+	mailer.token(t) // verification email
+	oldToken, _ := session["token"].(string)
+	created := mustCall(t, app, "POST", "/api/tokens", oldToken, `{"name":"ci","scopes":["view_all_task"]}`, http.StatusCreated)
+	apiToken, _ := created["token"].(string)
+
+	// Lock the account out from another client's IP.
+	cfg := testConfig()
+	limiter := throttle.NewLoginLimiter(stores.Logins, cfg.Login)
+	for i := 0; i < cfg.Login.MaxFailures; i++ {
+		if _, err := limiter.Fail(ctx, "198.51.100.7", email, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustCall(t, app, "POST", "/api/login", "", `{"email":"`+email+`","password":"password1"}`, http.StatusTooManyRequests)
+
+	// Issue times are whole seconds, so make sure the old session predates
+	// the second of the reset.
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	mustCall(t, app, "POST", "/api/forgot-password", "", `{"email":"`+email+`"}`, http.StatusAccepted)
+	mustCall(t, app, "POST", "/api/reset-password", "", `{"token":"`+mailer.token(t)+`","password":"password2"}`, http.StatusOK)
+
+	mustCall(t, app, "GET", "/api/user", oldToken, "", http.StatusUnauthorized)
+	mustCall(t, app, "GET", "/api/user", apiToken, "", http.StatusUnauthorized)
+
+	// A session started right after the reset, likely within the same
+	// second, is valid.
+	session = mustCall(t, app, "POST", "/api/login", "", `{"email":"`+email+`","password":"password2"}`, http.StatusOK)
+	newToken, _ := session["token"].(string)
+	mustCall(t, app, "GET", "/api/user", newToken, "", http.StatusOK)
+}
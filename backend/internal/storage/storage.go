@@ -0,0 +1,41 @@
+// Package storage keeps the content of uploaded files. Metadata lives in
+// the store package; a Storage only maps opaque keys to bytes, so other
+// backends such as S3-compatible object stores can be added alongside the
+// local filesystem one.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when no content is stored under a key.
+var ErrNotFound = errors.New("storage: not found")
+
+// Storage stores file content under keys chosen by the caller. Keys are
+// plain names without path separators.
+type Storage interface {
+	// Put stores the content of r under key, replacing any previous content.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open returns the content stored under key. The caller must close it.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the content stored under key. Deleting a missing key is
+	// not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+var errInvalidKey = errors.New("storage: invalid key")
+
+// validKey rejects keys that could escape the storage root.
+func validKey(key string) bool {
+	if key == "" || key == "." || key == ".." {
+		return false
+	}
+	for _, r := range key {
+		if r == '/' || r == '\\' || r == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"bytes"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewMemoryStores returns empty stores that keep everything in process
+// memory. They are safe for concurrent use and intended for tests and local
+// development.
+func NewMemoryStores() *Stores {
+	return &Stores{
+		Users:       &memoryUserStore{users: map[primitive.ObjectID]models.User{}},
+		Roles:       &memoryRoleStore{roles: map[primitive.ObjectID]models.Role{}},
+		Permissions: &memoryPermissionStore{permissions: map[primitive.ObjectID]models.Permission{}},
+		Projects:    &memoryProjectStore{projects: map[primitive.ObjectID]models.Project{}},
+		Members:     &memoryProjectMemberStore{members: map[primitive.ObjectID]models.ProjectMember{}},
+		Tasks:       &memoryTaskStore{tasks: map[primitive.ObjectID]models.Task{}},
+		TaskHistory: &memoryTaskHistoryStore{versions: map[primitive.ObjectID][]models.TaskVersion{}},
+		Comments:    &memoryCommentStore{comments: map[primitive.ObjectID]models.Comment{}},
+		Attachments: &memoryAttachmentStore{attachments: map[primitive.ObjectID]models.Attachment{}},
+		Tokens: &memoryTokenStore{
+			refreshTokens: map[string]models.RefreshToken{},
+			revokedTokens: map[string]models.RevokedToken{},
+			accountTokens: map[string]models.AccountToken{},
+		},
+		Logins:    NewMemoryLoginAttemptStore(),
+		MFA:       &memoryMFAStore{enrollments: map[primitive.ObjectID]models.MFA{}},
+		APITokens: &memoryAPITokenStore{tokens: map[primitive.ObjectID]models.APIToken{}},
+		Audit:     &memoryAuditStore{},
+	}
+}
+
+func compareIDs(a, b primitive.ObjectID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+func copyIDs(ids []primitive.ObjectID) []primitive.ObjectID {
+	if ids == nil {
+		return nil
+	}
+	return append([]primitive.ObjectID{}, ids...)
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
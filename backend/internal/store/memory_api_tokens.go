@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAPITokenStore struct {
+	mu     sync.Mutex
+	tokens map[primitive.ObjectID]models.APIToken
+}
+
+func (s *memoryAPITokenStore) Create(ctx context.Context, token *models.APIToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.tokens {
+		if existing.TokenHash == token.TokenHash {
+			return ErrDuplicate
+		}
+	}
+	token.ID = primitive.NewObjectID()
+	s.tokens[token.ID] = copyAPIToken(*token)
+	return nil
+}
+
+func (s *memoryAPITokenStore) FindByHash(ctx context.Context, tokenHash string, now time.Time) (*models.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, token := range s.tokens {
+		if token.TokenHash == tokenHash && token.ExpiresAt.After(now) {
+			token = copyAPIToken(token)
+			return &token, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memoryAPITokenStore) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := []models.APIToken{}
+	for _, token := range s.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, copyAPIToken(token))
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+		}
+		return compareIDs(tokens[i].ID, tokens[j].ID) > 0
+	})
+	return tokens, nil
+}
+
+func (s *memoryAPITokenStore) Touch(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[id]
+	if !ok {
+		return nil
+	}
+	token.LastUsedAt = &at
+	s.tokens[id] = token
+	return nil
+}
+
+func (s *memoryAPITokenStore) Delete(ctx context.Context, userID, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[id]
+	if !ok || token.UserID != userID {
+		return ErrNotFound
+	}
+	delete(s.tokens, id)
+	return nil
+}
+
+func copyAPIToken(token models.APIToken) models.APIToken {
+	token.Scopes = append([]string{}, token.Scopes...)
+	if token.LastUsedAt != nil {
+		lastUsed := *token.LastUsedAt
+		token.LastUsedAt = &lastUsed
+	}
+	return token
+}
+
+func (s *memoryAPITokenStore) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, token := range s.tokens {
+		if token.UserID == userID {
+			delete(s.tokens, id)
+		}
+	}
+	return nil
+}
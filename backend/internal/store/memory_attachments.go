@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAttachmentStore struct {
+	mu          sync.RWMutex
+	attachments map[primitive.ObjectID]models.Attachment
+}
+
+func (s *memoryAttachmentStore) Create(ctx context.Context, attachment *models.Attachment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attachment.ID = primitive.NewObjectID()
+	s.attachments[attachment.ID] = *attachment
+	return nil
+}
+
+func (s *memoryAttachmentStore) FindByID(ctx context.Context, taskID, id primitive.ObjectID) (*models.Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attachment, ok := s.attachments[id]
+	if !ok || attachment.TaskID != taskID {
+		return nil, ErrNotFound
+	}
+	return &attachment, nil
+}
+
+func (s *memoryAttachmentStore) List(ctx context.Context, taskID primitive.ObjectID) ([]models.Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filter([]primitive.ObjectID{taskID}), nil
+}
+
+func (s *memoryAttachmentStore) Delete(ctx context.Context, taskID, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attachment, ok := s.attachments[id]
+	if !ok || attachment.TaskID != taskID {
+		return ErrNotFound
+	}
+	delete(s.attachments, id)
+	return nil
+}
+
+func (s *memoryAttachmentStore) DeleteForTasks(ctx context.Context, taskIDs []primitive.ObjectID) ([]models.Attachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attachments := s.filter(taskIDs)
+	for _, attachment := range attachments {
+		delete(s.attachments, attachment.ID)
+	}
+	return attachments, nil
+}
+
+// filter must be called with the lock held.
+func (s *memoryAttachmentStore) filter(taskIDs []primitive.ObjectID) []models.Attachment {
+	attachments := []models.Attachment{}
+	for _, attachment := range s.attachments {
+		if containsID(taskIDs, attachment.TaskID) {
+			attachments = append(attachments, attachment)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		if !attachments[i].CreatedAt.Equal(attachments[j].CreatedAt) {
+			return attachments[i].CreatedAt.Before(attachments[j].CreatedAt)
+		}
+		return compareIDs(attachments[i].ID, attachments[j].ID) < 0
+	})
+	return attachments
+}
+
+func (s *memoryAttachmentStore) TaskIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []primitive.ObjectID
+	for _, attachment := range s.attachments {
+		if !containsID(ids, attachment.TaskID) {
+			ids = append(ids, attachment.TaskID)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAuditStore struct {
+	mu      sync.RWMutex
+	entries []models.AuditEntry
+}
+
+func (s *memoryAuditStore) Append(ctx context.Context, entry *models.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.ID = primitive.NewObjectID()
+	s.entries = append(s.entries, *entry)
+	return nil
+}
+
+func (s *memoryAuditStore) List(ctx context.Context, filter AuditFilter, skip, limit int) ([]models.AuditEntry, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := []models.AuditEntry{}
+	for _, entry := range s.entries {
+		if filter.ActorID != nil && (entry.ActorID == nil || *entry.ActorID != *filter.ActorID) {
+			continue
+		}
+		if filter.Action != "" && entry.Action != filter.Action {
+			continue
+		}
+		if filter.TargetID != nil && (entry.TargetID == nil || *entry.TargetID != *filter.TargetID) {
+			continue
+		}
+		if filter.From != nil && entry.CreatedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && entry.CreatedAt.After(*filter.To) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return compareIDs(matched[i].ID, matched[j].ID) > 0
+	})
+
+	total := int64(len(matched))
+	if skip > len(matched) {
+		skip = len(matched)
+	}
+	matched = matched[skip:]
+	if limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryCommentStore struct {
+	mu       sync.RWMutex
+	comments map[primitive.ObjectID]models.Comment
+}
+
+func (s *memoryCommentStore) Create(ctx context.Context, comment *models.Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment.ID = primitive.NewObjectID()
+	s.comments[comment.ID] = *comment
+	return nil
+}
+
+func (s *memoryCommentStore) FindByID(ctx context.Context, taskID, id primitive.ObjectID) (*models.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, ok := s.comments[id]
+	if !ok || comment.TaskID != taskID {
+		return nil, ErrNotFound
+	}
+	return &comment, nil
+}
+
+func (s *memoryCommentStore) List(ctx context.Context, taskID primitive.ObjectID) ([]models.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comments := []models.Comment{}
+	for _, comment := range s.comments {
+		if comment.TaskID == taskID {
+			comments = append(comments, comment)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return compareIDs(comments[i].ID, comments[j].ID) < 0
+	})
+	return comments, nil
+}
+
+func (s *memoryCommentStore) Update(ctx context.Context, taskID, id primitive.ObjectID, body string, at time.Time) (*models.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.comments[id]
+	if !ok || comment.TaskID != taskID {
+		return nil, ErrNotFound
+	}
+	comment.Body = body
+	comment.EditedAt = &at
+	s.comments[id] = comment
+	return &comment, nil
+}
+
+func (s *memoryCommentStore) Delete(ctx context.Context, taskID, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.comments[id]
+	if !ok || comment.TaskID != taskID {
+		return ErrNotFound
+	}
+	delete(s.comments, id)
+	return nil
+}
+
+func (s *memoryCommentStore) DeleteForTasks(ctx context.Context, taskIDs []primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, comment := range s.comments {
+		if containsID(taskIDs, comment.TaskID) {
+			delete(s.comments, id)
+		}
+	}
+	return nil
+}
+
+func (s *memoryCommentStore) TaskIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []primitive.ObjectID
+	for _, comment := range s.comments {
+		if !containsID(ids, comment.TaskID) {
+			ids = append(ids, comment.TaskID)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTaskHistoryStore struct {
+	mu       sync.RWMutex
+	versions map[primitive.ObjectID][]models.TaskVersion
+}
+
+func (s *memoryTaskHistoryStore) Append(ctx context.Context, version *models.TaskVersion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.versions[version.TaskID] {
+		if existing.Version == version.Version {
+			return ErrDuplicate
+		}
+	}
+	version.ID = primitive.NewObjectID()
+	snapshot := *version
+	snapshot.Task = *copyTask(version.Task)
+	s.versions[version.TaskID] = append(s.versions[version.TaskID], snapshot)
+	return nil
+}
+
+func (s *memoryTaskHistoryStore) List(ctx context.Context, taskID primitive.ObjectID) ([]models.TaskVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := []models.TaskVersion{}
+	for _, version := range s.versions[taskID] {
+		version.Task = *copyTask(version.Task)
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version > versions[j].Version
+	})
+	return versions, nil
+}
+
+func (s *memoryTaskHistoryStore) Find(ctx context.Context, taskID primitive.ObjectID, version int) (*models.TaskVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, snapshot := range s.versions[taskID] {
+		if snapshot.Version == version {
+			snapshot.Task = *copyTask(snapshot.Task)
+			return &snapshot, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memoryTaskHistoryStore) DeleteForTasks(ctx context.Context, taskIDs []primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range taskIDs {
+		delete(s.versions, id)
+	}
+	return nil
+}
+
+func (s *memoryTaskHistoryStore) TaskIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]primitive.ObjectID, 0, len(s.versions))
+	for id, versions := range s.versions {
+		if len(versions) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+)
+
+// pruneInterval is how often expired counters are dropped, standing in for
+// the TTL index of the Mongo store.
+const pruneInterval = time.Minute
+
+type memoryLoginAttemptStore struct {
+	mu         sync.Mutex
+	attempts   map[string]models.LoginAttempt
+	lastPruned time.Time
+}
+
+// NewMemoryLoginAttemptStore returns a login attempt store that keeps its
+// counters in process memory, for deployments with a single instance.
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+	return &memoryLoginAttemptStore{attempts: map[string]models.LoginAttempt{}}
+}
+
+func (s *memoryLoginAttemptStore) Find(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &attempt, nil
+}
+
+func (s *memoryLoginAttemptStore) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+	attempt := s.attempts[key]
+	attempt.Key = key
+	if attempt.LastFailure.After(now.Add(-window)) {
+		attempt.Failures++
+	} else {
+		attempt.Failures = 1
+	}
+	attempt.LastFailure = now
+	attempt.ExpiresAt = now.Add(window)
+	if attempt.LockedUntil.After(attempt.ExpiresAt) {
+		attempt.ExpiresAt = attempt.LockedUntil
+	}
+	s.attempts[key] = attempt
+	return &attempt, nil
+}
+
+func (s *memoryLoginAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt := s.attempts[key]
+	attempt.Key = key
+	if until.After(attempt.LockedUntil) {
+		attempt.LockedUntil = until
+	}
+	if until.After(attempt.ExpiresAt) {
+		attempt.ExpiresAt = until
+	}
+	s.attempts[key] = attempt
+	return nil
+}
+
+func (s *memoryLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+// prune drops expired counters at most once per pruneInterval, so that a
+// flood of failures from many addresses does not grow the map forever.
+func (s *memoryLoginAttemptStore) prune(now time.Time) {
+	if now.Sub(s.lastPruned) < pruneInterval {
+		return
+	}
+	s.lastPruned = now
+	for key, attempt := range s.attempts {
+		if !attempt.ExpiresAt.After(now) {
+			delete(s.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryMFAStore struct {
+	mu          sync.Mutex
+	enrollments map[primitive.ObjectID]models.MFA
+	policy      *models.MFAPolicy
+}
+
+func (s *memoryMFAStore) Find(ctx context.Context, userID primitive.ObjectID) (*models.MFA, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mfa, ok := s.enrollments[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	mfa.RecoveryCodes = append([]string(nil), mfa.RecoveryCodes...)
+	return &mfa, nil
+}
+
+func (s *memoryMFAStore) SavePending(ctx context.Context, mfa *models.MFA) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.enrollments[mfa.UserID]; ok && existing.Enabled {
+		return ErrDuplicate
+	}
+	mfa.Enabled = false
+	mfa.EnabledAt = nil
+	saved := *mfa
+	saved.RecoveryCodes = append([]string(nil), mfa.RecoveryCodes...)
+	s.enrollments[mfa.UserID] = saved
+	return nil
+}
+
+func (s *memoryMFAStore) Enable(ctx context.Context, userID primitive.ObjectID, recoveryCodes []string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mfa, ok := s.enrollments[userID]
+	if !ok || mfa.Enabled {
+		return ErrNotFound
+	}
+	mfa.Enabled = true
+	mfa.EnabledAt = &at
+	mfa.RecoveryCodes = append([]string(nil), recoveryCodes...)
+	s.enrollments[userID] = mfa
+	return nil
+}
+
+func (s *memoryMFAStore) SetRecoveryCodes(ctx context.Context, userID primitive.ObjectID, recoveryCodes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mfa, ok := s.enrollments[userID]
+	if !ok || !mfa.Enabled {
+		return ErrNotFound
+	}
+	mfa.RecoveryCodes = append([]string(nil), recoveryCodes...)
+	s.enrollments[userID] = mfa
+	return nil
+}
+
+func (s *memoryMFAStore) UseStep(ctx context.Context, userID primitive.ObjectID, step int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mfa, ok := s.enrollments[userID]
+	if !ok || mfa.LastStep >= step {
+		return ErrNotFound
+	}
+	mfa.LastStep = step
+	s.enrollments[userID] = mfa
+	return nil
+}
+
+func (s *memoryMFAStore) UseRecoveryCode(ctx context.Context, userID primitive.ObjectID, codeHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mfa, ok := s.enrollments[userID]
+	if !ok || !mfa.Enabled {
+		return ErrNotFound
+	}
+	for i, hash := range mfa.RecoveryCodes {
+		if hash == codeHash {
+			mfa.RecoveryCodes = append(mfa.RecoveryCodes[:i:i], mfa.RecoveryCodes[i+1:]...)
+			s.enrollments[userID] = mfa
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *memoryMFAStore) Delete(ctx context.Context, userID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.enrollments[userID]; !ok {
+		return ErrNotFound
+	}
+	delete(s.enrollments, userID)
+	return nil
+}
+
+func (s *memoryMFAStore) FindPolicy(ctx context.Context) (*models.MFAPolicy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.policy == nil {
+		return nil, ErrNotFound
+	}
+	policy := *s.policy
+	policy.Permissions = append([]string(nil), s.policy.Permissions...)
+	return &policy, nil
+}
+
+func (s *memoryMFAStore) SavePolicy(ctx context.Context, policy *models.MFAPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *policy
+	saved.Permissions = append([]string(nil), policy.Permissions...)
+	s.policy = &saved
+	return nil
+}
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryProjectStore struct {
+	mu       sync.RWMutex
+	projects map[primitive.ObjectID]models.Project
+}
+
+func (s *memoryProjectStore) Create(ctx context.Context, project *models.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project.ID = primitive.NewObjectID()
+	s.projects[project.ID] = *project
+	return nil
+}
+
+func (s *memoryProjectStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	project, ok := s.projects[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &project, nil
+}
+
+func (s *memoryProjectStore) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := []models.Project{}
+	for _, project := range s.projects {
+		if containsID(ids, project.ID) {
+			projects = append(projects, project)
+		}
+	}
+	sortProjects(projects)
+	return projects, nil
+}
+
+func (s *memoryProjectStore) Count(ctx context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.projects)), nil
+}
+
+func (s *memoryProjectStore) List(ctx context.Context) ([]models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := make([]models.Project, 0, len(s.projects))
+	for _, project := range s.projects {
+		projects = append(projects, project)
+	}
+	sortProjects(projects)
+	return projects, nil
+}
+
+func (s *memoryProjectStore) Update(ctx context.Context, id primitive.ObjectID, changes ProjectChanges) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if changes.Name != nil {
+		project.Name = *changes.Name
+	}
+	if changes.Description != nil {
+		project.Description = *changes.Description
+	}
+	s.projects[id] = project
+	return &project, nil
+}
+
+func sortProjects(projects []models.Project) {
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return compareIDs(projects[i].ID, projects[j].ID) < 0
+	})
+}
+
+type memoryProjectMemberStore struct {
+	mu      sync.RWMutex
+	members map[primitive.ObjectID]models.ProjectMember
+}
+
+func (s *memoryProjectMemberStore) Add(ctx context.Context, member *models.ProjectMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.members {
+		if existing.ProjectID == member.ProjectID && existing.UserID == member.UserID {
+			return ErrDuplicate
+		}
+	}
+	member.ID = primitive.NewObjectID()
+	s.members[member.ID] = *member
+	return nil
+}
+
+func (s *memoryProjectMemberStore) Find(ctx context.Context, projectID, userID primitive.ObjectID) (*models.ProjectMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	member, ok := s.find(projectID, userID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &member, nil
+}
+
+func (s *memoryProjectMemberStore) ListByProject(ctx context.Context, projectID primitive.ObjectID) ([]models.ProjectMember, error) {
+	return s.list(func(member models.ProjectMember) bool { return member.ProjectID == projectID }), nil
+}
+
+func (s *memoryProjectMemberStore) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.ProjectMember, error) {
+	return s.list(func(member models.ProjectMember) bool { return member.UserID == userID }), nil
+}
+
+func (s *memoryProjectMemberStore) CountMembers(ctx context.Context, projectID primitive.ObjectID, userIDs []primitive.ObjectID) (int64, error) {
+	members := s.list(func(member models.ProjectMember) bool {
+		return member.ProjectID == projectID && containsID(userIDs, member.UserID)
+	})
+	return int64(len(members)), nil
+}
+
+func (s *memoryProjectMemberStore) CountByRole(ctx context.Context, roleID primitive.ObjectID) (int64, error) {
+	members := s.list(func(member models.ProjectMember) bool { return member.RoleID == roleID })
+	return int64(len(members)), nil
+}
+
+func (s *memoryProjectMemberStore) CountByRoles(ctx context.Context, projectID primitive.ObjectID, roleIDs []primitive.ObjectID) (int64, error) {
+	members := s.list(func(member models.ProjectMember) bool {
+		return member.ProjectID == projectID && containsID(roleIDs, member.RoleID)
+	})
+	return int64(len(members)), nil
+}
+
+func (s *memoryProjectMemberStore) UpdateRole(ctx context.Context, projectID, userID, roleID primitive.ObjectID) (*models.ProjectMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.find(projectID, userID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	member.RoleID = roleID
+	s.members[member.ID] = member
+	return &member, nil
+}
+
+func (s *memoryProjectMemberStore) Remove(ctx context.Context, projectID, userID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.find(projectID, userID)
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.members, member.ID)
+	return nil
+}
+
+// find must be called with the lock held.
+func (s *memoryProjectMemberStore) find(projectID, userID primitive.ObjectID) (models.ProjectMember, bool) {
+	for _, member := range s.members {
+		if member.ProjectID == projectID && member.UserID == userID {
+			return member, true
+		}
+	}
+	return models.ProjectMember{}, false
+}
+
+func (s *memoryProjectMemberStore) list(match func(models.ProjectMember) bool) []models.ProjectMember {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	members := []models.ProjectMember{}
+	for _, member := range s.members {
+		if match(member) {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return compareIDs(members[i].ID, members[j].ID) < 0
+	})
+	return members
+}
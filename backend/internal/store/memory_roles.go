@@ -0,0 +1,229 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRoleStore struct {
+	mu    sync.RWMutex
+	roles map[primitive.ObjectID]models.Role
+}
+
+func (s *memoryRoleStore) Create(ctx context.Context, role *models.Role) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.nameTaken(role.Name, primitive.NilObjectID) {
+		return ErrDuplicate
+	}
+
+	role.ID = primitive.NewObjectID()
+	if role.Permissions == nil {
+		role.Permissions = []primitive.ObjectID{}
+	}
+	s.store(*role)
+	return nil
+}
+
+func (s *memoryRoleStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	role, ok := s.roles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyRole(role), nil
+}
+
+func (s *memoryRoleStore) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, role := range s.roles {
+		if role.Name == name {
+			return copyRole(role), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memoryRoleStore) List(ctx context.Context) ([]models.Role, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	roles := []models.Role{}
+	for _, role := range s.roles {
+		roles = append(roles, *copyRole(role))
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (s *memoryRoleStore) Rename(ctx context.Context, id primitive.ObjectID, name string) (*models.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	role, ok := s.roles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if s.nameTaken(name, id) {
+		return nil, ErrDuplicate
+	}
+	role.Name = name
+	s.store(role)
+	return copyRole(role), nil
+}
+
+func (s *memoryRoleStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.roles[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.roles, id)
+	return nil
+}
+
+func (s *memoryRoleStore) AddPermissions(ctx context.Context, id primitive.ObjectID, permissionIDs ...primitive.ObjectID) (*models.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	role, ok := s.roles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	role.Permissions = copyIDs(role.Permissions)
+	for _, permissionID := range permissionIDs {
+		if !containsID(role.Permissions, permissionID) {
+			role.Permissions = append(role.Permissions, permissionID)
+		}
+	}
+	s.store(role)
+	return copyRole(role), nil
+}
+
+func (s *memoryRoleStore) RemovePermission(ctx context.Context, id, permissionID primitive.ObjectID) (*models.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	role, ok := s.roles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	kept := []primitive.ObjectID{}
+	for _, existing := range role.Permissions {
+		if existing != permissionID {
+			kept = append(kept, existing)
+		}
+	}
+	role.Permissions = kept
+	s.store(role)
+	return copyRole(role), nil
+}
+
+func (s *memoryRoleStore) nameTaken(name string, except primitive.ObjectID) bool {
+	for id, role := range s.roles {
+		if role.Name == name && id != except {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memoryRoleStore) store(role models.Role) {
+	s.roles[role.ID] = *copyRole(role)
+}
+
+func copyRole(role models.Role) *models.Role {
+	role.Permissions = copyIDs(role.Permissions)
+	return &role
+}
+
+type memoryPermissionStore struct {
+	mu          sync.RWMutex
+	permissions map[primitive.ObjectID]models.Permission
+}
+
+func (s *memoryPermissionStore) Create(ctx context.Context, permission *models.Permission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.permissions {
+		if existing.Name == permission.Name {
+			return ErrDuplicate
+		}
+	}
+
+	permission.ID = primitive.NewObjectID()
+	s.permissions[permission.ID] = *permission
+	return nil
+}
+
+func (s *memoryPermissionStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Permission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	permission, ok := s.permissions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &permission, nil
+}
+
+func (s *memoryPermissionStore) FindByName(ctx context.Context, name string) (*models.Permission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, permission := range s.permissions {
+		if permission.Name == name {
+			return &permission, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memoryPermissionStore) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Permission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	permissions := []models.Permission{}
+	for _, permission := range s.permissions {
+		if containsID(ids, permission.ID) {
+			permissions = append(permissions, permission)
+		}
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+	return permissions, nil
+}
+
+func (s *memoryPermissionStore) List(ctx context.Context) ([]models.Permission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	permissions := []models.Permission{}
+	for _, permission := range s.permissions {
+		permissions = append(permissions, permission)
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+	return permissions, nil
+}
+
+func (s *memoryPermissionStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.permissions[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.permissions, id)
+	return nil
+}
@@ -0,0 +1,298 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTaskStore struct {
+	mu    sync.RWMutex
+	tasks map[primitive.ObjectID]models.Task
+}
+
+func (s *memoryTaskStore) Create(ctx context.Context, task *models.Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task.ID = primitive.NewObjectID()
+	s.tasks[task.ID] = *copyTask(*task)
+	return nil
+}
+
+func (s *memoryTaskStore) FindByID(ctx context.Context, id primitive.ObjectID, scope TaskScope) (*models.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !inScope(task, scope) {
+		return nil, ErrNotFound
+	}
+	return copyTask(task), nil
+}
+
+func (s *memoryTaskStore) Count(ctx context.Context, filter TaskFilter) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, task := range s.tasks {
+		if matchesFilter(task, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *memoryTaskStore) List(ctx context.Context, filter TaskFilter, page TaskPage) ([]models.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, task := range s.tasks {
+		if !matchesFilter(task, filter) {
+			continue
+		}
+		if page.After != nil {
+			position := compareTaskPosition(task, page.SortField, page.After.Value, page.After.ID)
+			if (page.Descending && position >= 0) || (!page.Descending && position <= 0) {
+				continue
+			}
+		}
+		tasks = append(tasks, *copyTask(task))
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		position := compareTaskPosition(tasks[i], page.SortField, sortValue(tasks[j], page.SortField), tasks[j].ID)
+		if page.Descending {
+			return position > 0
+		}
+		return position < 0
+	})
+
+	if page.Limit < len(tasks) {
+		tasks = tasks[:page.Limit]
+	}
+	return tasks, nil
+}
+
+func (s *memoryTaskStore) Update(ctx context.Context, id primitive.ObjectID, scope TaskScope, changes TaskChanges) (*models.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !inScope(task, scope) {
+		return nil, ErrNotFound
+	}
+	if changes.ExpectedStatus != nil && task.Status != *changes.ExpectedStatus {
+		return nil, ErrConflict
+	}
+	if changes.Name != nil {
+		task.Name = *changes.Name
+	}
+	if changes.Description != nil {
+		task.Description = *changes.Description
+	}
+	if changes.Status != nil {
+		task.Status = *changes.Status
+	}
+	if changes.Priority != nil {
+		task.Priority = *changes.Priority
+	}
+	if changes.ClearDueDate {
+		task.DueDate = nil
+	} else if changes.DueDate != nil {
+		dueDate := *changes.DueDate
+		task.DueDate = &dueDate
+	}
+	if changes.Labels != nil {
+		task.Labels = append([]string{}, *changes.Labels...)
+	}
+	if changes.Assignees != nil {
+		task.Assignees = copyIDs(*changes.Assignees)
+	}
+	task.UpdatedAt = changes.UpdatedAt
+	task.Version++
+	s.tasks[id] = task
+	return copyTask(task), nil
+}
+
+func (s *memoryTaskStore) Delete(ctx context.Context, id primitive.ObjectID, scope TaskScope, deletedBy primitive.ObjectID, at time.Time) (*models.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scope.Trash = false
+	task, ok := s.tasks[id]
+	if !ok || !inScope(task, scope) {
+		return nil, ErrNotFound
+	}
+	task.DeletedAt = &at
+	task.DeletedBy = &deletedBy
+	task.UpdatedAt = at
+	task.Version++
+	s.tasks[id] = task
+	return copyTask(task), nil
+}
+
+func (s *memoryTaskStore) Restore(ctx context.Context, id primitive.ObjectID, scope TaskScope, at time.Time) (*models.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scope.Trash = true
+	task, ok := s.tasks[id]
+	if !ok || !inScope(task, scope) {
+		return nil, ErrNotFound
+	}
+	task.DeletedAt = nil
+	task.DeletedBy = nil
+	task.UpdatedAt = at
+	task.Version++
+	s.tasks[id] = task
+	return copyTask(task), nil
+}
+
+func (s *memoryTaskStore) Purge(ctx context.Context, deletedBefore time.Time) ([]primitive.ObjectID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []primitive.ObjectID
+	for id, task := range s.tasks {
+		if task.DeletedAt != nil && task.DeletedAt.Before(deletedBefore) {
+			delete(s.tasks, id)
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *memoryTaskStore) FindExisting(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var existing []primitive.ObjectID
+	for _, id := range ids {
+		if _, ok := s.tasks[id]; ok {
+			existing = append(existing, id)
+		}
+	}
+	return existing, nil
+}
+
+func (s *memoryTaskStore) AdoptOrphans(ctx context.Context, projectID primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var moved int64
+	for id, task := range s.tasks {
+		if task.ProjectID.IsZero() {
+			task.ProjectID = projectID
+			s.tasks[id] = task
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+func copyTask(task models.Task) *models.Task {
+	task.Assignees = copyIDs(task.Assignees)
+	if task.Labels != nil {
+		task.Labels = append([]string{}, task.Labels...)
+	}
+	return &task
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+func inScope(task models.Task, scope TaskScope) bool {
+	if (task.DeletedAt != nil) != scope.Trash {
+		return false
+	}
+	if scope.ProjectID != nil && task.ProjectID != *scope.ProjectID {
+		return false
+	}
+	if scope.VisibleTo == nil {
+		return true
+	}
+	return task.CreatedBy == *scope.VisibleTo || containsID(task.Assignees, *scope.VisibleTo)
+}
+
+func matchesFilter(task models.Task, filter TaskFilter) bool {
+	if !inScope(task, filter.TaskScope) {
+		return false
+	}
+	if filter.Status != "" && task.Status != filter.Status {
+		return false
+	}
+	if filter.Priority != "" && task.Priority != filter.Priority {
+		return false
+	}
+	if filter.Label != "" && !containsString(task.Labels, filter.Label) {
+		return false
+	}
+	if filter.Search != "" {
+		search := strings.ToLower(filter.Search)
+		if !strings.Contains(strings.ToLower(task.Name), search) &&
+			!strings.Contains(strings.ToLower(task.Description), search) {
+			return false
+		}
+	}
+	if filter.CreatedFrom != nil && task.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && task.CreatedAt.After(*filter.CreatedTo) {
+		return false
+	}
+	if filter.UpdatedFrom != nil && task.UpdatedAt.Before(*filter.UpdatedFrom) {
+		return false
+	}
+	if filter.UpdatedTo != nil && task.UpdatedAt.After(*filter.UpdatedTo) {
+		return false
+	}
+	if filter.DueFrom != nil && (task.DueDate == nil || task.DueDate.Before(*filter.DueFrom)) {
+		return false
+	}
+	if filter.DueTo != nil && (task.DueDate == nil || task.DueDate.After(*filter.DueTo)) {
+		return false
+	}
+	return true
+}
+
+func sortValue(task models.Task, field string) interface{} {
+	switch field {
+	case TaskSortName:
+		return task.Name
+	case TaskSortUpdatedAt:
+		return task.UpdatedAt
+	default:
+		return task.CreatedAt
+	}
+}
+
+// compareTaskPosition orders task against the position (value, id) in a
+// listing sorted by field and then ID.
+func compareTaskPosition(task models.Task, field string, value interface{}, id primitive.ObjectID) int {
+	var result int
+	switch v := value.(type) {
+	case string:
+		result = strings.Compare(task.Name, v)
+	case time.Time:
+		result = sortValue(task, field).(time.Time).Compare(v)
+	}
+	if result != 0 {
+		return result
+	}
+	return compareIDs(task.ID, id)
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTaskUpdateExpectedStatus(t *testing.T) {
+	ctx := context.Background()
+	stores := NewMemoryStores()
+
+	task := models.Task{ProjectID: primitive.NewObjectID(), Name: "Task", Status: "todo", CreatedAt: time.Now(), Version: 1}
+	if err := stores.Tasks.Create(ctx, &task); err != nil {
+		t.Fatal(err)
+	}
+
+	inProgress, todo := "in_progress", "todo"
+	updated, err := stores.Tasks.Update(ctx, task.ID, TaskScope{}, TaskChanges{Status: &inProgress, ExpectedStatus: &todo, UpdatedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != inProgress || updated.Version != 2 {
+		t.Fatalf("updated task = %q version %d, want %q version 2", updated.Status, updated.Version, inProgress)
+	}
+
+	// A second transition checked against the old status must not apply.
+	done := "done"
+	_, err = stores.Tasks.Update(ctx, task.ID, TaskScope{}, TaskChanges{Status: &done, ExpectedStatus: &todo, UpdatedAt: time.Now()})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("stale update = %v, want ErrConflict", err)
+	}
+	current, err := stores.Tasks.FindByID(ctx, task.ID, TaskScope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Status != inProgress || current.Version != 2 {
+		t.Errorf("task after stale update = %q version %d, want %q version 2", current.Status, current.Version, inProgress)
+	}
+
+	_, err = stores.Tasks.Update(ctx, primitive.NewObjectID(), TaskScope{}, TaskChanges{Status: &done, ExpectedStatus: &todo, UpdatedAt: time.Now()})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("update of a missing task = %v, want ErrNotFound", err)
+	}
+}
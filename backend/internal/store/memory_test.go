@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMemoryRolesAreCopied(t *testing.T) {
+	ctx := context.Background()
+	stores := NewMemoryStores()
+
+	permission := primitive.NewObjectID()
+	role := models.Role{Name: "ops", Permissions: []primitive.ObjectID{permission}}
+	if err := stores.Roles.Create(ctx, &role); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing what a caller holds must not change the stored role.
+	role.Permissions[0] = primitive.NewObjectID()
+	found, err := stores.Roles.FindByID(ctx, role.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Permissions[0] != permission {
+		t.Fatal("role changed through the value passed to Create")
+	}
+	found.Permissions[0] = primitive.NewObjectID()
+	again, err := stores.Roles.FindByID(ctx, role.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Permissions[0] != permission {
+		t.Error("role changed through the value returned by FindByID")
+	}
+}
+
+func TestMemoryStoresReportMissingRecords(t *testing.T) {
+	ctx := context.Background()
+	stores := NewMemoryStores()
+	missing := primitive.NewObjectID()
+
+	if _, err := stores.Users.FindByID(ctx, missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Users.FindByID = %v, want ErrNotFound", err)
+	}
+	if _, err := stores.Roles.FindByName(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Roles.FindByName = %v, want ErrNotFound", err)
+	}
+	if err := stores.Roles.Delete(ctx, missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Roles.Delete = %v, want ErrNotFound", err)
+	}
+	if _, err := stores.Tasks.FindByID(ctx, missing, TaskScope{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Tasks.FindByID = %v, want ErrNotFound", err)
+	}
+}
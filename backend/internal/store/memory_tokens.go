@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTokenStore struct {
+	mu            sync.Mutex
+	refreshTokens map[string]models.RefreshToken
+	revokedTokens map[string]models.RevokedToken
+	accountTokens map[string]models.AccountToken
+}
+
+func (s *memoryTokenStore) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.refreshTokens[token.TokenHash]; ok {
+		return ErrDuplicate
+	}
+	token.ID = primitive.NewObjectID()
+	s.refreshTokens[token.TokenHash] = *token
+	return nil
+}
+
+func (s *memoryTokenStore) FindRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.refreshTokens[tokenHash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &token, nil
+}
+
+func (s *memoryTokenStore) UseRefreshToken(ctx context.Context, tokenHash string, now time.Time) (*models.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.refreshTokens[tokenHash]
+	if !ok || token.Used || token.Revoked || !token.ExpiresAt.After(now) {
+		return nil, ErrNotFound
+	}
+	token.Used = true
+	s.refreshTokens[tokenHash] = token
+	return &token, nil
+}
+
+func (s *memoryTokenStore) RevokeRefreshFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, token := range s.refreshTokens {
+		if token.FamilyID == familyID {
+			token.Revoked = true
+			s.refreshTokens[hash] = token
+		}
+	}
+	return nil
+}
+
+func (s *memoryTokenStore) RevokeAccessToken(ctx context.Context, token models.RevokedToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revokedTokens[token.JTI] = token
+	return nil
+}
+
+func (s *memoryTokenStore) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.revokedTokens[jti]
+	return ok, nil
+}
+
+func (s *memoryTokenStore) RevokeUserRefreshTokens(ctx context.Context, userID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, token := range s.refreshTokens {
+		if token.UserID == userID {
+			token.Revoked = true
+			s.refreshTokens[hash] = token
+		}
+	}
+	return nil
+}
+
+func (s *memoryTokenStore) CreateAccountToken(ctx context.Context, token *models.AccountToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accountTokens[token.TokenHash]; ok {
+		return ErrDuplicate
+	}
+	token.ID = primitive.NewObjectID()
+	s.accountTokens[token.TokenHash] = *token
+	return nil
+}
+
+func (s *memoryTokenStore) UseAccountToken(ctx context.Context, tokenHash, purpose string, now time.Time) (*models.AccountToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.accountTokens[tokenHash]
+	if !ok || token.Purpose != purpose || !token.ExpiresAt.After(now) {
+		return nil, ErrNotFound
+	}
+	delete(s.accountTokens, tokenHash)
+	return &token, nil
+}
+
+func (s *memoryTokenStore) FindAccountToken(ctx context.Context, tokenHash, purpose string, now time.Time) (*models.AccountToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.accountTokens[tokenHash]
+	if !ok || token.Purpose != purpose || !token.ExpiresAt.After(now) {
+		return nil, ErrNotFound
+	}
+	return &token, nil
+}
+
+func (s *memoryTokenStore) DeleteAccountTokens(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, token := range s.accountTokens {
+		if token.UserID == userID && token.Purpose == purpose {
+			delete(s.accountTokens, hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryUserStore struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]models.User
+}
+
+func (s *memoryUserStore) Create(ctx context.Context, user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.users {
+		if strings.EqualFold(existing.Email, user.Email) {
+			return ErrDuplicate
+		}
+	}
+
+	user.ID = primitive.NewObjectID()
+	s.users[user.ID] = *user
+	return nil
+}
+
+func (s *memoryUserStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (s *memoryUserStore) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if strings.EqualFold(user.Email, email) {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memoryUserStore) Count(ctx context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.users)), nil
+}
+
+func (s *memoryUserStore) CountByRole(ctx context.Context, roleID primitive.ObjectID) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, user := range s.users {
+		if user.RoleID == roleID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *memoryUserStore) CountEnabledByRoles(ctx context.Context, roleIDs []primitive.ObjectID) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, user := range s.users {
+		if !user.Disabled && containsID(roleIDs, user.RoleID) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *memoryUserStore) CountExisting(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := map[primitive.ObjectID]bool{}
+	for _, id := range ids {
+		if _, ok := s.users[id]; ok {
+			seen[id] = true
+		}
+	}
+	return int64(len(seen)), nil
+}
+
+func (s *memoryUserStore) List(ctx context.Context, filter UserFilter, skip, limit int) ([]models.User, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	search := strings.ToLower(filter.Search)
+	matched := []models.User{}
+	for _, user := range s.users {
+		if search != "" &&
+			!strings.Contains(strings.ToLower(user.Name), search) &&
+			!strings.Contains(strings.ToLower(user.Email), search) {
+			continue
+		}
+		matched = append(matched, user)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return compareIDs(matched[i].ID, matched[j].ID) < 0
+	})
+
+	total := int64(len(matched))
+	if skip > len(matched) {
+		skip = len(matched)
+	}
+	matched = matched[skip:]
+	if limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+func (s *memoryUserStore) Update(ctx context.Context, id primitive.ObjectID, changes UserChanges) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if changes.RoleID != nil {
+		user.RoleID = *changes.RoleID
+	}
+	if changes.Disabled != nil {
+		user.Disabled = *changes.Disabled
+	}
+	if changes.Password != nil {
+		user.Password = changes.Password
+	}
+	if changes.EmailVerified != nil {
+		user.EmailVerified = *changes.EmailVerified
+	}
+	if changes.SessionsValidAfter != nil {
+		validAfter := *changes.SessionsValidAfter
+		user.SessionsValidAfter = &validAfter
+	}
+	s.users[id] = user
+	return &user, nil
+}
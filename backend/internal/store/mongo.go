@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NewMongoStores returns stores backed by collections in db.
+func NewMongoStores(db *mongo.Database) *Stores {
+	return &Stores{
+		Users:       &mongoUserStore{collection: db.Collection("users")},
+		Roles:       &mongoRoleStore{collection: db.Collection("roles")},
+		Permissions: &mongoPermissionStore{collection: db.Collection("permissions")},
+		Projects:    &mongoProjectStore{collection: db.Collection("projects")},
+		Members:     &mongoProjectMemberStore{collection: db.Collection("project_members")},
+		Tasks:       &mongoTaskStore{collection: db.Collection("tasks")},
+		TaskHistory: &mongoTaskHistoryStore{collection: db.Collection("task_history")},
+		Comments:    &mongoCommentStore{collection: db.Collection("task_comments")},
+		Attachments: &mongoAttachmentStore{collection: db.Collection("task_attachments")},
+		Tokens: &mongoTokenStore{
+			refreshTokens: db.Collection("refresh_tokens"),
+			revokedTokens: db.Collection("revoked_tokens"),
+			accountTokens: db.Collection("account_tokens"),
+		},
+		Logins: &mongoLoginAttemptStore{collection: db.Collection("login_attempts")},
+		MFA: &mongoMFAStore{
+			enrollments: db.Collection("mfa_enrollments"),
+			settings:    db.Collection("settings"),
+		},
+		APITokens: &mongoAPITokenStore{collection: db.Collection("api_tokens")},
+		Audit:     &mongoAuditStore{collection: db.Collection("audit_log")},
+	}
+}
+
+// notFound maps mongo.ErrNoDocuments to ErrNotFound.
+func notFound(err error) error {
+	if err == mongo.ErrNoDocuments {
+		return ErrNotFound
+	}
+	return err
+}
+
+// distinctTaskIDs returns the distinct task_id values in collection.
+func distinctTaskIDs(ctx context.Context, collection *mongo.Collection) ([]primitive.ObjectID, error) {
+	values, err := collection.Distinct(ctx, "task_id", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoAPITokenStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoAPITokenStore) Create(ctx context.Context, token *models.APIToken) error {
+	token.ID = primitive.NilObjectID
+	result, err := s.collection.InsertOne(ctx, token)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	token.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoAPITokenStore) FindByHash(ctx context.Context, tokenHash string, now time.Time) (*models.APIToken, error) {
+	var token models.APIToken
+	err := s.collection.FindOne(ctx, bson.M{
+		"token_hash": tokenHash,
+		"expires_at": bson.M{"$gt": now},
+	}).Decode(&token)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (s *mongoAPITokenStore) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.APIToken, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := s.collection.Find(ctx, bson.M{"user_id": userID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tokens := []models.APIToken{}
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (s *mongoAPITokenStore) Touch(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	_, err := s.collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"last_used_at": at}})
+	return err
+}
+
+func (s *mongoAPITokenStore) Delete(ctx context.Context, userID, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *mongoAPITokenStore) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
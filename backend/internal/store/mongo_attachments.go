@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoAttachmentStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoAttachmentStore) Create(ctx context.Context, attachment *models.Attachment) error {
+	attachment.ID = primitive.NilObjectID
+	result, err := s.collection.InsertOne(ctx, attachment)
+	if err != nil {
+		return err
+	}
+	attachment.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoAttachmentStore) FindByID(ctx context.Context, taskID, id primitive.ObjectID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := s.collection.FindOne(ctx, bson.M{"_id": id, "task_id": taskID}).Decode(&attachment); err != nil {
+		return nil, notFound(err)
+	}
+	return &attachment, nil
+}
+
+func (s *mongoAttachmentStore) List(ctx context.Context, taskID primitive.ObjectID) ([]models.Attachment, error) {
+	return s.find(ctx, bson.M{"task_id": taskID})
+}
+
+func (s *mongoAttachmentStore) Delete(ctx context.Context, taskID, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id, "task_id": taskID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *mongoAttachmentStore) DeleteForTasks(ctx context.Context, taskIDs []primitive.ObjectID) ([]models.Attachment, error) {
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+
+	query := bson.M{"task_id": bson.M{"$in": taskIDs}}
+	attachments, err := s.find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.collection.DeleteMany(ctx, query); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (s *mongoAttachmentStore) find(ctx context.Context, query bson.M) ([]models.Attachment, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := s.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	attachments := []models.Attachment{}
+	if err := cursor.All(ctx, &attachments); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (s *mongoAttachmentStore) TaskIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	return distinctTaskIDs(ctx, s.collection)
+}
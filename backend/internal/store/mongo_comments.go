@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoCommentStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoCommentStore) Create(ctx context.Context, comment *models.Comment) error {
+	comment.ID = primitive.NilObjectID
+	result, err := s.collection.InsertOne(ctx, comment)
+	if err != nil {
+		return err
+	}
+	comment.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoCommentStore) FindByID(ctx context.Context, taskID, id primitive.ObjectID) (*models.Comment, error) {
+	var comment models.Comment
+	if err := s.collection.FindOne(ctx, bson.M{"_id": id, "task_id": taskID}).Decode(&comment); err != nil {
+		return nil, notFound(err)
+	}
+	return &comment, nil
+}
+
+func (s *mongoCommentStore) List(ctx context.Context, taskID primitive.ObjectID) ([]models.Comment, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := s.collection.Find(ctx, bson.M{"task_id": taskID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := []models.Comment{}
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (s *mongoCommentStore) Update(ctx context.Context, taskID, id primitive.ObjectID, body string, at time.Time) (*models.Comment, error) {
+	var comment models.Comment
+	err := s.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id, "task_id": taskID},
+		bson.M{"$set": bson.M{"body": body, "edited_at": at}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&comment)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &comment, nil
+}
+
+func (s *mongoCommentStore) Delete(ctx context.Context, taskID, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id, "task_id": taskID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *mongoCommentStore) DeleteForTasks(ctx context.Context, taskIDs []primitive.ObjectID) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	_, err := s.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}})
+	return err
+}
+
+func (s *mongoCommentStore) TaskIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	return distinctTaskIDs(ctx, s.collection)
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoTaskHistoryStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoTaskHistoryStore) Append(ctx context.Context, version *models.TaskVersion) error {
+	version.ID = primitive.NilObjectID
+	result, err := s.collection.InsertOne(ctx, version)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	version.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoTaskHistoryStore) List(ctx context.Context, taskID primitive.ObjectID) ([]models.TaskVersion, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+	cursor, err := s.collection.Find(ctx, bson.M{"task_id": taskID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := []models.TaskVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (s *mongoTaskHistoryStore) Find(ctx context.Context, taskID primitive.ObjectID, version int) (*models.TaskVersion, error) {
+	var snapshot models.TaskVersion
+	err := s.collection.FindOne(ctx, bson.M{"task_id": taskID, "version": version}).Decode(&snapshot)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &snapshot, nil
+}
+
+func (s *mongoTaskHistoryStore) DeleteForTasks(ctx context.Context, taskIDs []primitive.ObjectID) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	_, err := s.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}})
+	return err
+}
+
+func (s *mongoTaskHistoryStore) TaskIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	return distinctTaskIDs(ctx, s.collection)
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoLoginAttemptStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoLoginAttemptStore) Find(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	if err := s.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&attempt); err != nil {
+		return nil, notFound(err)
+	}
+	return &attempt, nil
+}
+
+func (s *mongoLoginAttemptStore) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	// An update pipeline lets the reset of a stale counter and the increment
+	// happen in one atomic operation. A missing last_failure compares lower
+	// than any date, so a new counter starts at one.
+	update := bson.A{
+		bson.M{"$set": bson.M{
+			"failures": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{"$last_failure", now.Add(-window)}},
+				bson.M{"$add": bson.A{"$failures", 1}},
+				1,
+			}},
+			"last_failure": now,
+			"expires_at":   bson.M{"$max": bson.A{"$locked_until", now.Add(window)}},
+		}},
+	}
+
+	var attempt models.LoginAttempt
+	err := s.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": key},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&attempt)
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (s *mongoLoginAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := s.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": key},
+		bson.M{"$max": bson.M{"locked_until": until, "expires_at": until}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (s *mongoLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mfaPolicyID is the _id of the single policy document in the settings
+// collection.
+const mfaPolicyID = "mfa_policy"
+
+type mongoMFAStore struct {
+	enrollments *mongo.Collection
+	settings    *mongo.Collection
+}
+
+func (s *mongoMFAStore) Find(ctx context.Context, userID primitive.ObjectID) (*models.MFA, error) {
+	var mfa models.MFA
+	if err := s.enrollments.FindOne(ctx, bson.M{"_id": userID}).Decode(&mfa); err != nil {
+		return nil, notFound(err)
+	}
+	return &mfa, nil
+}
+
+func (s *mongoMFAStore) SavePending(ctx context.Context, mfa *models.MFA) error {
+	mfa.Enabled = false
+	mfa.EnabledAt = nil
+	// An enabled enrollment does not match the filter, so the upsert tries
+	// to insert a second document with the same _id and fails.
+	_, err := s.enrollments.ReplaceOne(
+		ctx,
+		bson.M{"_id": mfa.UserID, "enabled": false},
+		mfa,
+		options.Replace().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (s *mongoMFAStore) Enable(ctx context.Context, userID primitive.ObjectID, recoveryCodes []string, at time.Time) error {
+	result, err := s.enrollments.UpdateOne(
+		ctx,
+		bson.M{"_id": userID, "enabled": false},
+		bson.M{"$set": bson.M{"enabled": true, "enabled_at": at, "recovery_codes": recoveryCodes}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *mongoMFAStore) SetRecoveryCodes(ctx context.Context, userID primitive.ObjectID, recoveryCodes []string) error {
+	result, err := s.enrollments.UpdateOne(
+		ctx,
+		bson.M{"_id": userID, "enabled": true},
+		bson.M{"$set": bson.M{"recovery_codes": recoveryCodes}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *mongoMFAStore) UseStep(ctx context.Context, userID primitive.ObjectID, step int64) error {
+	result, err := s.enrollments.UpdateOne(
+		ctx,
+		bson.M{"_id": userID, "last_step": bson.M{"$lt": step}},
+		bson.M{"$set": bson.M{"last_step": step}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *mongoMFAStore) UseRecoveryCode(ctx context.Context, userID primitive.ObjectID, codeHash string) error {
+	result, err := s.enrollments.UpdateOne(
+		ctx,
+		bson.M{"_id": userID, "enabled": true, "recovery_codes": codeHash},
+		bson.M{"$pull": bson.M{"recovery_codes": codeHash}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *mongoMFAStore) Delete(ctx context.Context, userID primitive.ObjectID) error {
+	result, err := s.enrollments.DeleteOne(ctx, bson.M{"_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *mongoMFAStore) FindPolicy(ctx context.Context) (*models.MFAPolicy, error) {
+	var policy models.MFAPolicy
+	if err := s.settings.FindOne(ctx, bson.M{"_id": mfaPolicyID}).Decode(&policy); err != nil {
+		return nil, notFound(err)
+	}
+	return &policy, nil
+}
+
+func (s *mongoMFAStore) SavePolicy(ctx context.Context, policy *models.MFAPolicy) error {
+	_, err := s.settings.UpdateOne(
+		ctx,
+		bson.M{"_id": mfaPolicyID},
+		bson.M{"$set": policy},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoProjectStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoProjectStore) Create(ctx context.Context, project *models.Project) error {
+	project.ID = primitive.NilObjectID
+	result, err := s.collection.InsertOne(ctx, project)
+	if err != nil {
+		return err
+	}
+	project.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoProjectStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Project, error) {
+	var project models.Project
+	if err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&project); err != nil {
+		return nil, notFound(err)
+	}
+	return &project, nil
+}
+
+func (s *mongoProjectStore) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Project, error) {
+	return s.find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+}
+
+func (s *mongoProjectStore) Count(ctx context.Context) (int64, error) {
+	return s.collection.CountDocuments(ctx, bson.M{})
+}
+
+func (s *mongoProjectStore) List(ctx context.Context) ([]models.Project, error) {
+	return s.find(ctx, bson.M{})
+}
+
+func (s *mongoProjectStore) Update(ctx context.Context, id primitive.ObjectID, changes ProjectChanges) (*models.Project, error) {
+	set := bson.M{}
+	if changes.Name != nil {
+		set["name"] = *changes.Name
+	}
+	if changes.Description != nil {
+		set["description"] = *changes.Description
+	}
+	if len(set) == 0 {
+		return s.FindByID(ctx, id)
+	}
+
+	var project models.Project
+	err := s.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&project)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &project, nil
+}
+
+func (s *mongoProjectStore) find(ctx context.Context, query bson.M) ([]models.Project, error) {
+	cursor, err := s.collection.Find(ctx, query, options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	projects := []models.Project{}
+	if err := cursor.All(ctx, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+type mongoProjectMemberStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoProjectMemberStore) Add(ctx context.Context, member *models.ProjectMember) error {
+	member.ID = primitive.NilObjectID
+	result, err := s.collection.InsertOne(ctx, member)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	member.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoProjectMemberStore) Find(ctx context.Context, projectID, userID primitive.ObjectID) (*models.ProjectMember, error) {
+	var member models.ProjectMember
+	err := s.collection.FindOne(ctx, bson.M{"project_id": projectID, "user_id": userID}).Decode(&member)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &member, nil
+}
+
+func (s *mongoProjectMemberStore) ListByProject(ctx context.Context, projectID primitive.ObjectID) ([]models.ProjectMember, error) {
+	return s.find(ctx, bson.M{"project_id": projectID})
+}
+
+func (s *mongoProjectMemberStore) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.ProjectMember, error) {
+	return s.find(ctx, bson.M{"user_id": userID})
+}
+
+func (s *mongoProjectMemberStore) CountMembers(ctx context.Context, projectID primitive.ObjectID, userIDs []primitive.ObjectID) (int64, error) {
+	return s.collection.CountDocuments(ctx, bson.M{"project_id": projectID, "user_id": bson.M{"$in": userIDs}})
+}
+
+func (s *mongoProjectMemberStore) CountByRole(ctx context.Context, roleID primitive.ObjectID) (int64, error) {
+	return s.collection.CountDocuments(ctx, bson.M{"role_id": roleID})
+}
+
+func (s *mongoProjectMemberStore) CountByRoles(ctx context.Context, projectID primitive.ObjectID, roleIDs []primitive.ObjectID) (int64, error) {
+	if len(roleIDs) == 0 {
+		return 0, nil
+	}
+	return s.collection.CountDocuments(ctx, bson.M{"project_id": projectID, "role_id": bson.M{"$in": roleIDs}})
+}
+
+func (s *mongoProjectMemberStore) UpdateRole(ctx context.Context, projectID, userID, roleID primitive.ObjectID) (*models.ProjectMember, error) {
+	var member models.ProjectMember
+	err := s.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"project_id": projectID, "user_id": userID},
+		bson.M{"$set": bson.M{"role_id": roleID}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&member)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &member, nil
+}
+
+func (s *mongoProjectMemberStore) Remove(ctx context.Context, projectID, userID primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"project_id": projectID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *mongoProjectMemberStore) find(ctx context.Context, query bson.M) ([]models.ProjectMember, error) {
+	cursor, err := s.collection.Find(ctx, query, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	members := []models.ProjectMember{}
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRoleStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoRoleStore) Create(ctx context.Context, role *models.Role) error {
+	if taken, err := s.nameTaken(ctx, role.Name, primitive.NilObjectID); err != nil {
+		return err
+	} else if taken {
+		return ErrDuplicate
+	}
+
+	if role.Permissions == nil {
+		role.Permissions = []primitive.ObjectID{}
+	}
+	result, err := s.collection.InsertOne(ctx, role)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	role.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoRoleStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Role, error) {
+	return s.findOne(ctx, bson.M{"_id": id})
+}
+
+func (s *mongoRoleStore) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	return s.findOne(ctx, bson.M{"name": name})
+}
+
+func (s *mongoRoleStore) List(ctx context.Context) ([]models.Role, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	roles := []models.Role{}
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (s *mongoRoleStore) Rename(ctx context.Context, id primitive.ObjectID, name string) (*models.Role, error) {
+	if taken, err := s.nameTaken(ctx, name, id); err != nil {
+		return nil, err
+	} else if taken {
+		return nil, ErrDuplicate
+	}
+	return s.update(ctx, id, bson.M{"$set": bson.M{"name": name}})
+}
+
+func (s *mongoRoleStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *mongoRoleStore) AddPermissions(ctx context.Context, id primitive.ObjectID, permissionIDs ...primitive.ObjectID) (*models.Role, error) {
+	return s.update(ctx, id, bson.M{"$addToSet": bson.M{"permissions": bson.M{"$each": permissionIDs}}})
+}
+
+func (s *mongoRoleStore) RemovePermission(ctx context.Context, id, permissionID primitive.ObjectID) (*models.Role, error) {
+	return s.update(ctx, id, bson.M{"$pull": bson.M{"permissions": permissionID}})
+}
+
+func (s *mongoRoleStore) findOne(ctx context.Context, filter bson.M) (*models.Role, error) {
+	var role models.Role
+	if err := s.collection.FindOne(ctx, filter).Decode(&role); err != nil {
+		return nil, notFound(err)
+	}
+	return &role, nil
+}
+
+func (s *mongoRoleStore) update(ctx context.Context, id primitive.ObjectID, update bson.M) (*models.Role, error) {
+	var role models.Role
+	err := s.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&role)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &role, nil
+}
+
+func (s *mongoRoleStore) nameTaken(ctx context.Context, name string, except primitive.ObjectID) (bool, error) {
+	filter := bson.M{"name": name}
+	if !except.IsZero() {
+		filter["_id"] = bson.M{"$ne": except}
+	}
+	count, err := s.collection.CountDocuments(ctx, filter)
+	return count > 0, err
+}
+
+type mongoPermissionStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoPermissionStore) Create(ctx context.Context, permission *models.Permission) error {
+	result, err := s.collection.InsertOne(ctx, permission)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	permission.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoPermissionStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Permission, error) {
+	return s.findOne(ctx, bson.M{"_id": id})
+}
+
+func (s *mongoPermissionStore) FindByName(ctx context.Context, name string) (*models.Permission, error) {
+	return s.findOne(ctx, bson.M{"name": name})
+}
+
+func (s *mongoPermissionStore) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Permission, error) {
+	return s.find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+}
+
+func (s *mongoPermissionStore) List(ctx context.Context) ([]models.Permission, error) {
+	return s.find(ctx, bson.M{})
+}
+
+func (s *mongoPermissionStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *mongoPermissionStore) findOne(ctx context.Context, filter bson.M) (*models.Permission, error) {
+	var permission models.Permission
+	if err := s.collection.FindOne(ctx, filter).Decode(&permission); err != nil {
+		return nil, notFound(err)
+	}
+	return &permission, nil
+}
+
+func (s *mongoPermissionStore) find(ctx context.Context, filter bson.M) ([]models.Permission, error) {
+	cursor, err := s.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	permissions := []models.Permission{}
+	if err := cursor.All(ctx, &permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
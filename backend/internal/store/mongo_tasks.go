@@ -0,0 +1,283 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoTaskStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoTaskStore) Create(ctx context.Context, task *models.Task) error {
+	result, err := s.collection.InsertOne(ctx, task)
+	if err != nil {
+		return err
+	}
+	task.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoTaskStore) FindByID(ctx context.Context, id primitive.ObjectID, scope TaskScope) (*models.Task, error) {
+	var task models.Task
+	if err := s.collection.FindOne(ctx, scopeQuery(scope, id)).Decode(&task); err != nil {
+		return nil, notFound(err)
+	}
+	return &task, nil
+}
+
+func (s *mongoTaskStore) Count(ctx context.Context, filter TaskFilter) (int64, error) {
+	return s.collection.CountDocuments(ctx, bson.M{"$and": filterConditions(filter)})
+}
+
+func (s *mongoTaskStore) List(ctx context.Context, filter TaskFilter, page TaskPage) ([]models.Task, error) {
+	conditions := filterConditions(filter)
+
+	operator, direction := "$gt", 1
+	if page.Descending {
+		operator, direction = "$lt", -1
+	}
+	if page.After != nil {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{page.SortField: bson.M{operator: page.After.Value}},
+			bson.M{page.SortField: page.After.Value, "_id": bson.M{operator: page.After.ID}},
+		}})
+	}
+
+	// _id breaks ties so the order is total and cursors are stable.
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: page.SortField, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(page.Limit))
+	cursor, err := s.collection.Find(ctx, bson.M{"$and": conditions}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tasks := []models.Task{}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (s *mongoTaskStore) Update(ctx context.Context, id primitive.ObjectID, scope TaskScope, changes TaskChanges) (*models.Task, error) {
+	set := bson.M{"updated_at": changes.UpdatedAt}
+	if changes.Name != nil {
+		set["name"] = *changes.Name
+	}
+	if changes.Description != nil {
+		set["description"] = *changes.Description
+	}
+	if changes.Status != nil {
+		set["status"] = *changes.Status
+	}
+	if changes.Priority != nil {
+		set["priority"] = *changes.Priority
+	}
+	if changes.DueDate != nil && !changes.ClearDueDate {
+		set["due_date"] = *changes.DueDate
+	}
+	if changes.Labels != nil {
+		set["labels"] = *changes.Labels
+	}
+	if changes.Assignees != nil {
+		set["assignees"] = *changes.Assignees
+	}
+
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	if changes.ClearDueDate {
+		update["$unset"] = bson.M{"due_date": ""}
+	}
+	query := scopeQuery(scope, id)
+	if changes.ExpectedStatus == nil {
+		return s.findAndUpdate(ctx, query, update)
+	}
+
+	query["status"] = *changes.ExpectedStatus
+	task, err := s.findAndUpdate(ctx, query, update)
+	if errors.Is(err, ErrNotFound) {
+		delete(query, "status")
+		if err := s.collection.FindOne(ctx, query).Err(); err != nil {
+			return nil, notFound(err)
+		}
+		return nil, ErrConflict
+	}
+	return task, err
+}
+
+func (s *mongoTaskStore) Delete(ctx context.Context, id primitive.ObjectID, scope TaskScope, deletedBy primitive.ObjectID, at time.Time) (*models.Task, error) {
+	scope.Trash = false
+	return s.findAndUpdate(ctx, scopeQuery(scope, id), bson.M{
+		"$set": bson.M{"deleted_at": at, "deleted_by": deletedBy, "updated_at": at},
+		"$inc": bson.M{"version": 1},
+	})
+}
+
+func (s *mongoTaskStore) Restore(ctx context.Context, id primitive.ObjectID, scope TaskScope, at time.Time) (*models.Task, error) {
+	scope.Trash = true
+	return s.findAndUpdate(ctx, scopeQuery(scope, id), bson.M{
+		"$set":   bson.M{"updated_at": at},
+		"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+		"$inc":   bson.M{"version": 1},
+	})
+}
+
+func (s *mongoTaskStore) Purge(ctx context.Context, deletedBefore time.Time) ([]primitive.ObjectID, error) {
+	query := bson.M{"deleted_at": bson.M{"$lt": deletedBefore}}
+	cursor, err := s.collection.Find(ctx, query, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	// Delete one task at a time, re-checking deleted_at, so that a task
+	// restored in the meantime survives and is not reported as purged.
+	var purged []primitive.ObjectID
+	for _, id := range ids {
+		result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$lt": deletedBefore}})
+		if err != nil {
+			return purged, err
+		}
+		if result.DeletedCount == 1 {
+			purged = append(purged, id)
+		}
+	}
+	return purged, nil
+}
+
+func (s *mongoTaskStore) FindExisting(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	cursor, err := s.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var existing []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		existing = append(existing, doc.ID)
+	}
+	return existing, cursor.Err()
+}
+
+func (s *mongoTaskStore) AdoptOrphans(ctx context.Context, projectID primitive.ObjectID) (int64, error) {
+	result, err := s.collection.UpdateMany(
+		ctx,
+		bson.M{"project_id": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"project_id": projectID}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (s *mongoTaskStore) findAndUpdate(ctx context.Context, query, update bson.M) (*models.Task, error) {
+	var task models.Task
+	err := s.collection.FindOneAndUpdate(
+		ctx,
+		query,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&task)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &task, nil
+}
+
+// scopeQuery matches the task with the given ID if it is within scope.
+func scopeQuery(scope TaskScope, id primitive.ObjectID) bson.M {
+	return bson.M{"$and": append(scopeConditions(scope), bson.M{"_id": id})}
+}
+
+func scopeConditions(scope TaskScope) bson.A {
+	conditions := bson.A{bson.M{"deleted_at": nil}}
+	if scope.Trash {
+		conditions = bson.A{bson.M{"deleted_at": bson.M{"$ne": nil}}}
+	}
+	if scope.ProjectID != nil {
+		conditions = append(conditions, bson.M{"project_id": *scope.ProjectID})
+	}
+	if scope.VisibleTo != nil {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"created_by": *scope.VisibleTo},
+			bson.M{"assignees": *scope.VisibleTo},
+		}})
+	}
+	return conditions
+}
+
+// filterConditions returns the conditions of filter as a list suitable for
+// $and. The list is never empty, since $and rejects empty arrays; the scope
+// always contributes the deleted_at condition.
+func filterConditions(filter TaskFilter) bson.A {
+	conditions := scopeConditions(filter.TaskScope)
+
+	if filter.Status != "" {
+		conditions = append(conditions, bson.M{"status": filter.Status})
+	}
+	if filter.Priority != "" {
+		conditions = append(conditions, bson.M{"priority": filter.Priority})
+	}
+	if filter.Label != "" {
+		conditions = append(conditions, bson.M{"labels": filter.Label})
+	}
+	if filter.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(filter.Search), Options: "i"}
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"name": pattern},
+			bson.M{"description": pattern},
+		}})
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, bson.M{"created_at": bson.M{"$gte": *filter.CreatedFrom}})
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, bson.M{"created_at": bson.M{"$lte": *filter.CreatedTo}})
+	}
+	if filter.UpdatedFrom != nil {
+		conditions = append(conditions, bson.M{"updated_at": bson.M{"$gte": *filter.UpdatedFrom}})
+	}
+	if filter.UpdatedTo != nil {
+		conditions = append(conditions, bson.M{"updated_at": bson.M{"$lte": *filter.UpdatedTo}})
+	}
+	if filter.DueFrom != nil {
+		conditions = append(conditions, bson.M{"due_date": bson.M{"$gte": *filter.DueFrom}})
+	}
+	if filter.DueTo != nil {
+		conditions = append(conditions, bson.M{"due_date": bson.M{"$lte": *filter.DueTo}})
+	}
+	return conditions
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoTokenStore struct {
+	refreshTokens *mongo.Collection
+	revokedTokens *mongo.Collection
+	accountTokens *mongo.Collection
+}
+
+func (s *mongoTokenStore) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	result, err := s.refreshTokens.InsertOne(ctx, token)
+	if err != nil {
+		return err
+	}
+	token.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoTokenStore) FindRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := s.refreshTokens.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&token); err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (s *mongoTokenStore) UseRefreshToken(ctx context.Context, tokenHash string, now time.Time) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := s.refreshTokens.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"token_hash": tokenHash,
+			"used":       false,
+			"revoked":    false,
+			"expires_at": bson.M{"$gt": now},
+		},
+		bson.M{"$set": bson.M{"used": true}},
+	).Decode(&token)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (s *mongoTokenStore) RevokeRefreshFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	_, err := s.refreshTokens.UpdateMany(
+		ctx,
+		bson.M{"family_id": familyID},
+		bson.M{"$set": bson.M{"revoked": true}},
+	)
+	return err
+}
+
+func (s *mongoTokenStore) RevokeAccessToken(ctx context.Context, token models.RevokedToken) error {
+	_, err := s.revokedTokens.InsertOne(ctx, token)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *mongoTokenStore) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	err := s.revokedTokens.FindOne(ctx, bson.M{"_id": jti}).Err()
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *mongoTokenStore) RevokeUserRefreshTokens(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := s.refreshTokens.UpdateMany(
+		ctx,
+		bson.M{"user_id": userID},
+		bson.M{"$set": bson.M{"revoked": true}},
+	)
+	return err
+}
+
+func (s *mongoTokenStore) CreateAccountToken(ctx context.Context, token *models.AccountToken) error {
+	token.ID = primitive.NilObjectID
+	result, err := s.accountTokens.InsertOne(ctx, token)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	token.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoTokenStore) UseAccountToken(ctx context.Context, tokenHash, purpose string, now time.Time) (*models.AccountToken, error) {
+	var token models.AccountToken
+	err := s.accountTokens.FindOneAndDelete(ctx, bson.M{
+		"token_hash": tokenHash,
+		"purpose":    purpose,
+		"expires_at": bson.M{"$gt": now},
+	}).Decode(&token)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (s *mongoTokenStore) FindAccountToken(ctx context.Context, tokenHash, purpose string, now time.Time) (*models.AccountToken, error) {
+	var token models.AccountToken
+	err := s.accountTokens.FindOne(ctx, bson.M{
+		"token_hash": tokenHash,
+		"purpose":    purpose,
+		"expires_at": bson.M{"$gt": now},
+	}).Decode(&token)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (s *mongoTokenStore) DeleteAccountTokens(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	_, err := s.accountTokens.DeleteMany(ctx, bson.M{"user_id": userID, "purpose": purpose})
+	return err
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"regexp"
+
+	"backend/internal/database"
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoUserStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoUserStore) Create(ctx context.Context, user *models.User) error {
+	err := s.collection.FindOne(ctx, bson.M{"email": user.Email}, options.FindOne().SetCollation(database.EmailCollation)).Err()
+	if err == nil {
+		return ErrDuplicate
+	}
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	result, err := s.collection.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	user.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *mongoUserStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	var user models.User
+	if err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user); err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (s *mongoUserStore) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	err := s.collection.FindOne(ctx, bson.M{"email": email}, options.FindOne().SetCollation(database.EmailCollation)).Decode(&user)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (s *mongoUserStore) Count(ctx context.Context) (int64, error) {
+	return s.collection.CountDocuments(ctx, bson.M{})
+}
+
+func (s *mongoUserStore) CountByRole(ctx context.Context, roleID primitive.ObjectID) (int64, error) {
+	return s.collection.CountDocuments(ctx, bson.M{"role_id": roleID})
+}
+
+func (s *mongoUserStore) CountEnabledByRoles(ctx context.Context, roleIDs []primitive.ObjectID) (int64, error) {
+	if len(roleIDs) == 0 {
+		return 0, nil
+	}
+	return s.collection.CountDocuments(ctx, bson.M{"role_id": bson.M{"$in": roleIDs}, "disabled": bson.M{"$ne": true}})
+}
+
+func (s *mongoUserStore) CountExisting(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	return s.collection.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": ids}})
+}
+
+func (s *mongoUserStore) List(ctx context.Context, filter UserFilter, skip, limit int) ([]models.User, int64, error) {
+	query := bson.M{}
+	if filter.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(filter.Search), Options: "i"}
+		query["$or"] = bson.A{
+			bson.M{"name": pattern},
+			bson.M{"email": pattern},
+		}
+	}
+
+	total, err := s.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.M{"_id": 1}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+	cursor, err := s.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (s *mongoUserStore) Update(ctx context.Context, id primitive.ObjectID, changes UserChanges) (*models.User, error) {
+	set := bson.M{}
+	if changes.RoleID != nil {
+		set["role_id"] = *changes.RoleID
+	}
+	if changes.Disabled != nil {
+		set["disabled"] = *changes.Disabled
+	}
+	if changes.Password != nil {
+		set["password"] = changes.Password
+	}
+	if changes.EmailVerified != nil {
+		set["email_verified"] = *changes.EmailVerified
+	}
+	if changes.SessionsValidAfter != nil {
+		set["sessions_valid_after"] = *changes.SessionsValidAfter
+	}
+	if len(set) == 0 {
+		return s.FindByID(ctx, id)
+	}
+
+	var user models.User
+	err := s.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
@@ -0,0 +1,385 @@
+// Package store defines the persistence interfaces used by the HTTP
+// handlers, together with a MongoDB implementation and an in-memory
+// implementation for tests and local development.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrNotFound is returned when no document matches.
+	ErrNotFound = errors.New("store: not found")
+	// ErrDuplicate is returned when a write violates a uniqueness rule.
+	ErrDuplicate = errors.New("store: duplicate")
+	// ErrConflict is returned when a conditional write finds the document
+	// changed since it was read.
+	ErrConflict = errors.New("store: conflict")
+)
+
+// Stores bundles every store the application needs.
+type Stores struct {
+	Users       UserStore
+	Roles       RoleStore
+	Permissions PermissionStore
+	Projects    ProjectStore
+	Members     ProjectMemberStore
+	Tasks       TaskStore
+	TaskHistory TaskHistoryStore
+	Comments    CommentStore
+	Attachments AttachmentStore
+	Tokens      TokenStore
+	Logins      LoginAttemptStore
+	MFA         MFAStore
+	APITokens   APITokenStore
+	Audit       AuditStore
+}
+
+// UserFilter selects users for listing.
+type UserFilter struct {
+	// Search matches name or email case-insensitively.
+	Search string
+}
+
+// UserChanges lists the user fields to update. Nil fields are untouched.
+type UserChanges struct {
+	RoleID        *primitive.ObjectID
+	Disabled      *bool
+	Password      []byte
+	EmailVerified *bool
+	// SessionsValidAfter invalidates the user's access tokens issued before
+	// this time.
+	SessionsValidAfter *time.Time
+}
+
+type UserStore interface {
+	// Create inserts the user and sets its ID. It returns ErrDuplicate when
+	// the email is already registered, compared case-insensitively.
+	Create(ctx context.Context, user *models.User) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	Count(ctx context.Context) (int64, error)
+	CountByRole(ctx context.Context, roleID primitive.ObjectID) (int64, error)
+	// CountEnabledByRoles counts the users who are not disabled and whose
+	// global role is one of roleIDs.
+	CountEnabledByRoles(ctx context.Context, roleIDs []primitive.ObjectID) (int64, error)
+	// CountExisting returns how many of the given IDs belong to users.
+	CountExisting(ctx context.Context, ids []primitive.ObjectID) (int64, error)
+	// List returns users ordered by ID along with the total number matching
+	// the filter.
+	List(ctx context.Context, filter UserFilter, skip, limit int) ([]models.User, int64, error)
+	Update(ctx context.Context, id primitive.ObjectID, changes UserChanges) (*models.User, error)
+}
+
+type RoleStore interface {
+	// Create inserts the role and sets its ID. It returns ErrDuplicate when
+	// the name is taken.
+	Create(ctx context.Context, role *models.Role) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Role, error)
+	FindByName(ctx context.Context, name string) (*models.Role, error)
+	List(ctx context.Context) ([]models.Role, error)
+	// Rename returns ErrDuplicate when another role already has the name.
+	Rename(ctx context.Context, id primitive.ObjectID, name string) (*models.Role, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	AddPermissions(ctx context.Context, id primitive.ObjectID, permissionIDs ...primitive.ObjectID) (*models.Role, error)
+	RemovePermission(ctx context.Context, id, permissionID primitive.ObjectID) (*models.Role, error)
+}
+
+type PermissionStore interface {
+	Create(ctx context.Context, permission *models.Permission) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Permission, error)
+	FindByName(ctx context.Context, name string) (*models.Permission, error)
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Permission, error)
+	List(ctx context.Context) ([]models.Permission, error)
+	// Delete removes the permission. Roles granting it must be updated
+	// separately.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// ProjectChanges lists the project fields to update. Nil fields are
+// untouched.
+type ProjectChanges struct {
+	Name        *string
+	Description *string
+}
+
+type ProjectStore interface {
+	// Create inserts the project and sets its ID.
+	Create(ctx context.Context, project *models.Project) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Project, error)
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Project, error)
+	Count(ctx context.Context) (int64, error)
+	// List returns every project ordered by name.
+	List(ctx context.Context) ([]models.Project, error)
+	Update(ctx context.Context, id primitive.ObjectID, changes ProjectChanges) (*models.Project, error)
+}
+
+type ProjectMemberStore interface {
+	// Add inserts the membership and sets its ID. It returns ErrDuplicate
+	// when the user is already a member of the project.
+	Add(ctx context.Context, member *models.ProjectMember) error
+	Find(ctx context.Context, projectID, userID primitive.ObjectID) (*models.ProjectMember, error)
+	ListByProject(ctx context.Context, projectID primitive.ObjectID) ([]models.ProjectMember, error)
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.ProjectMember, error)
+	// CountMembers returns how many of the given users belong to the project.
+	CountMembers(ctx context.Context, projectID primitive.ObjectID, userIDs []primitive.ObjectID) (int64, error)
+	CountByRole(ctx context.Context, roleID primitive.ObjectID) (int64, error)
+	// CountByRoles counts the project's members whose role is one of
+	// roleIDs.
+	CountByRoles(ctx context.Context, projectID primitive.ObjectID, roleIDs []primitive.ObjectID) (int64, error)
+	UpdateRole(ctx context.Context, projectID, userID, roleID primitive.ObjectID) (*models.ProjectMember, error)
+	Remove(ctx context.Context, projectID, userID primitive.ObjectID) error
+}
+
+// TaskScope restricts task operations to what a caller may see.
+type TaskScope struct {
+	// ProjectID, when set, limits results to tasks of that project.
+	ProjectID *primitive.ObjectID
+	// VisibleTo, when set, limits results to tasks created by or assigned
+	// to that user.
+	VisibleTo *primitive.ObjectID
+	// Trash selects tasks that have been deleted instead of live ones.
+	Trash bool
+}
+
+// TaskFilter selects tasks for listing and counting.
+type TaskFilter struct {
+	TaskScope
+	Status      string
+	Priority    string
+	Label       string
+	Search      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	DueFrom     *time.Time
+	DueTo       *time.Time
+}
+
+// Fields a task listing can be sorted by.
+const (
+	TaskSortCreatedAt = "created_at"
+	TaskSortUpdatedAt = "updated_at"
+	TaskSortName      = "name"
+)
+
+// TaskPage describes one page of a keyset-paginated task listing. Results
+// are ordered by SortField and then by ID.
+type TaskPage struct {
+	SortField  string
+	Descending bool
+	Limit      int
+	// After, when set, skips every task up to and including this position.
+	After *TaskCursor
+}
+
+// TaskCursor is a position in a sorted task listing. Value is a string for
+// TaskSortName and a time.Time otherwise.
+type TaskCursor struct {
+	Value interface{}
+	ID    primitive.ObjectID
+}
+
+// TaskChanges lists the task fields to update. Nil fields are untouched.
+type TaskChanges struct {
+	Name        *string
+	Description *string
+	Status      *string
+	Priority    *string
+	DueDate     *time.Time
+	// ClearDueDate removes the due date. It takes precedence over DueDate.
+	ClearDueDate bool
+	Labels       *[]string
+	Assignees    *[]primitive.ObjectID
+	UpdatedAt    time.Time
+	// ExpectedStatus makes Update fail with ErrConflict unless the task
+	// still has this status, so that a workflow transition checked against
+	// an earlier read cannot apply to a task that has moved on since.
+	ExpectedStatus *string
+}
+
+type TaskStore interface {
+	// Create inserts the task and sets its ID.
+	Create(ctx context.Context, task *models.Task) error
+	FindByID(ctx context.Context, id primitive.ObjectID, scope TaskScope) (*models.Task, error)
+	Count(ctx context.Context, filter TaskFilter) (int64, error)
+	List(ctx context.Context, filter TaskFilter, page TaskPage) ([]models.Task, error)
+	// Update applies changes and increments the task's version.
+	Update(ctx context.Context, id primitive.ObjectID, scope TaskScope, changes TaskChanges) (*models.Task, error)
+	// Delete moves a live task to the trash and increments its version.
+	Delete(ctx context.Context, id primitive.ObjectID, scope TaskScope, deletedBy primitive.ObjectID, at time.Time) (*models.Task, error)
+	// Restore moves a task out of the trash and increments its version.
+	Restore(ctx context.Context, id primitive.ObjectID, scope TaskScope, at time.Time) (*models.Task, error)
+	// Purge permanently removes tasks deleted before the cutoff and returns
+	// the IDs of those it removed, also when it fails part way. Tasks
+	// restored while it runs are neither removed nor returned.
+	Purge(ctx context.Context, deletedBefore time.Time) ([]primitive.ObjectID, error)
+	// FindExisting returns those of ids that belong to a task, live or in
+	// the trash.
+	FindExisting(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error)
+	// AdoptOrphans moves every task that belongs to no project into the
+	// given project and returns how many were moved.
+	AdoptOrphans(ctx context.Context, projectID primitive.ObjectID) (int64, error)
+}
+
+type TaskHistoryStore interface {
+	// Append stores a snapshot and sets its ID. It returns ErrDuplicate when
+	// the task already has a snapshot with the same version.
+	Append(ctx context.Context, version *models.TaskVersion) error
+	// List returns every snapshot of a task, newest first.
+	List(ctx context.Context, taskID primitive.ObjectID) ([]models.TaskVersion, error)
+	Find(ctx context.Context, taskID primitive.ObjectID, version int) (*models.TaskVersion, error)
+	// DeleteForTasks removes the history of purged tasks.
+	DeleteForTasks(ctx context.Context, taskIDs []primitive.ObjectID) error
+	// TaskIDs returns the IDs of the tasks that have history.
+	TaskIDs(ctx context.Context) ([]primitive.ObjectID, error)
+}
+
+// CommentStore persists the comments posted on tasks. Every lookup is keyed
+// by task as well as comment ID so a comment cannot be reached through
+// another task's URL.
+type CommentStore interface {
+	// Create stores the comment and sets its ID.
+	Create(ctx context.Context, comment *models.Comment) error
+	FindByID(ctx context.Context, taskID, id primitive.ObjectID) (*models.Comment, error)
+	// List returns the comments of a task, oldest first.
+	List(ctx context.Context, taskID primitive.ObjectID) ([]models.Comment, error)
+	// Update replaces the body and sets edited_at.
+	Update(ctx context.Context, taskID, id primitive.ObjectID, body string, at time.Time) (*models.Comment, error)
+	Delete(ctx context.Context, taskID, id primitive.ObjectID) error
+	// DeleteForTasks removes the comments of purged tasks.
+	DeleteForTasks(ctx context.Context, taskIDs []primitive.ObjectID) error
+	// TaskIDs returns the IDs of the tasks that have comments.
+	TaskIDs(ctx context.Context) ([]primitive.ObjectID, error)
+}
+
+// AttachmentStore persists the metadata of files attached to tasks. The
+// content itself is kept by a storage.Storage under StorageKey.
+type AttachmentStore interface {
+	// Create stores the metadata and sets its ID.
+	Create(ctx context.Context, attachment *models.Attachment) error
+	FindByID(ctx context.Context, taskID, id primitive.ObjectID) (*models.Attachment, error)
+	// List returns the attachments of a task, oldest first.
+	List(ctx context.Context, taskID primitive.ObjectID) ([]models.Attachment, error)
+	Delete(ctx context.Context, taskID, id primitive.ObjectID) error
+	// DeleteForTasks removes the attachments of purged tasks and returns
+	// them so their content can be removed too.
+	DeleteForTasks(ctx context.Context, taskIDs []primitive.ObjectID) ([]models.Attachment, error)
+	// TaskIDs returns the IDs of the tasks that have attachments.
+	TaskIDs(ctx context.Context) ([]primitive.ObjectID, error)
+}
+
+type TokenStore interface {
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	FindRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// UseRefreshToken atomically marks an unused, unrevoked and unexpired
+	// refresh token as used and returns it. It returns ErrNotFound when no
+	// such token exists.
+	UseRefreshToken(ctx context.Context, tokenHash string, now time.Time) (*models.RefreshToken, error)
+	RevokeRefreshFamily(ctx context.Context, familyID primitive.ObjectID) error
+	// RevokeAccessToken adds a jti to the denylist. Revoking twice is not an
+	// error.
+	RevokeAccessToken(ctx context.Context, token models.RevokedToken) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	// RevokeUserRefreshTokens revokes every refresh token of a user, ending
+	// all of their sessions once their access tokens expire.
+	RevokeUserRefreshTokens(ctx context.Context, userID primitive.ObjectID) error
+
+	CreateAccountToken(ctx context.Context, token *models.AccountToken) error
+	// UseAccountToken atomically deletes an unexpired token with the given
+	// hash and purpose and returns it. It returns ErrNotFound when no such
+	// token exists, so each token works at most once.
+	UseAccountToken(ctx context.Context, tokenHash, purpose string, now time.Time) (*models.AccountToken, error)
+	// FindAccountToken returns an unexpired token without using it up, or
+	// ErrNotFound.
+	FindAccountToken(ctx context.Context, tokenHash, purpose string, now time.Time) (*models.AccountToken, error)
+	// DeleteAccountTokens removes a user's outstanding tokens for a purpose.
+	DeleteAccountTokens(ctx context.Context, userID primitive.ObjectID, purpose string) error
+}
+
+// LoginAttemptStore counts failed logins per key, such as a client IP or an
+// email address.
+type LoginAttemptStore interface {
+	// Find returns the counter for key, or ErrNotFound when there is none.
+	Find(ctx context.Context, key string) (*models.LoginAttempt, error)
+	// RecordFailure atomically adds a failure to key and returns the updated
+	// counter. A counter whose last failure is older than window starts
+	// over.
+	RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error)
+	// Lock blocks attempts for key until the given time, unless it is
+	// already blocked for longer.
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Reset forgets every failure recorded for key.
+	Reset(ctx context.Context, key string) error
+}
+
+// MFAStore keeps TOTP enrollments and the policy deciding who needs one.
+type MFAStore interface {
+	// Find returns the user's enrollment, pending or enabled, or
+	// ErrNotFound.
+	Find(ctx context.Context, userID primitive.ObjectID) (*models.MFA, error)
+	// SavePending stores a new pending enrollment, replacing an earlier
+	// pending one. It returns ErrDuplicate when MFA is already enabled.
+	SavePending(ctx context.Context, mfa *models.MFA) error
+	// Enable turns a pending enrollment on with the given recovery code
+	// hashes. It returns ErrNotFound when nothing is pending.
+	Enable(ctx context.Context, userID primitive.ObjectID, recoveryCodes []string, at time.Time) error
+	SetRecoveryCodes(ctx context.Context, userID primitive.ObjectID, recoveryCodes []string) error
+	// UseStep atomically records step as the last accepted time step. It
+	// returns ErrNotFound when step is not newer than the last one, which
+	// means the code was already used.
+	UseStep(ctx context.Context, userID primitive.ObjectID, step int64) error
+	// UseRecoveryCode atomically removes a recovery code hash. It returns
+	// ErrNotFound when the code is not one of the user's unused codes.
+	UseRecoveryCode(ctx context.Context, userID primitive.ObjectID, codeHash string) error
+	Delete(ctx context.Context, userID primitive.ObjectID) error
+
+	// FindPolicy returns the saved policy, or ErrNotFound when none has
+	// been saved yet.
+	FindPolicy(ctx context.Context) (*models.MFAPolicy, error)
+	SavePolicy(ctx context.Context, policy *models.MFAPolicy) error
+}
+
+type APITokenStore interface {
+	// Create inserts the token and sets its ID.
+	Create(ctx context.Context, token *models.APIToken) error
+	// FindByHash returns the unexpired token with the given hash, or
+	// ErrNotFound.
+	FindByHash(ctx context.Context, tokenHash string, now time.Time) (*models.APIToken, error)
+	// ListByUser returns a user's tokens, newest first, including expired
+	// ones that have not been cleaned up yet.
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.APIToken, error)
+	// Touch records when a token was last used.
+	Touch(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	// Delete revokes one of a user's tokens. It returns ErrNotFound when the
+	// user has no such token.
+	Delete(ctx context.Context, userID, id primitive.ObjectID) error
+	// DeleteByUser revokes all of a user's tokens.
+	DeleteByUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// AuditFilter selects audit entries. Zero fields match everything.
+type AuditFilter struct {
+	ActorID  *primitive.ObjectID
+	Action   string
+	TargetID *primitive.ObjectID
+	From     *time.Time
+	To       *time.Time
+}
+
+// AuditStore is append-only: entries cannot be changed or removed through
+// it.
+type AuditStore interface {
+	// Append inserts the entry and sets its ID.
+	Append(ctx context.Context, entry *models.AuditEntry) error
+	// List returns entries newest first along with the total number matching
+	// the filter.
+	List(ctx context.Context, filter AuditFilter, skip, limit int) ([]models.AuditEntry, int64, error)
+}
@@ -0,0 +1,108 @@
+// Package throttle slows down and locks out repeated failed logins.
+package throttle
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/store"
+)
+
+// LoginLimiter tracks failed logins both per client IP, which catches one
+// client trying many accounts, and per email address, which catches many
+// clients trying one account.
+type LoginLimiter struct {
+	attempts store.LoginAttemptStore
+	cfg      config.Login
+}
+
+func NewLoginLimiter(attempts store.LoginAttemptStore, cfg config.Login) *LoginLimiter {
+	return &LoginLimiter{attempts: attempts, cfg: cfg}
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// emailKey lowercases the address because emails are matched
+// case-insensitively at login.
+func emailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// Wait returns how long the client must wait before it may try to log in
+// as email from ip. Zero means the attempt is allowed.
+func (l *LoginLimiter) Wait(ctx context.Context, ip, email string, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+	for _, key := range []string{ipKey(ip), emailKey(email)} {
+		attempt, err := l.attempts.Find(ctx, key)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if remaining := attempt.LockedUntil.Sub(now); remaining > wait {
+			wait = remaining
+		}
+	}
+	return wait, nil
+}
+
+// Fail records a failed login and blocks further attempts for the backoff
+// the failure counts call for. locked reports whether this failure reached
+// the limit for email, locking the account out.
+func (l *LoginLimiter) Fail(ctx context.Context, ip, email string, now time.Time) (locked bool, err error) {
+	limits := []struct {
+		key         string
+		maxFailures int
+	}{
+		{ipKey(ip), l.cfg.MaxFailuresPerIP},
+		{emailKey(email), l.cfg.MaxFailures},
+	}
+	for _, limit := range limits {
+		attempt, err := l.attempts.RecordFailure(ctx, limit.key, now, l.cfg.FailureWindow)
+		if err != nil {
+			return false, err
+		}
+		delay := l.delay(attempt.Failures, limit.maxFailures)
+		if delay <= 0 {
+			continue
+		}
+		if err := l.attempts.Lock(ctx, limit.key, now.Add(delay)); err != nil {
+			return false, err
+		}
+		if limit.key == emailKey(email) && attempt.Failures == limit.maxFailures {
+			locked = true
+		}
+	}
+	return locked, nil
+}
+
+// Reset forgets the failures recorded for email, after a successful login
+// or when an administrator unlocks the account. Failures per IP are kept so
+// that a client cannot clear its own count by logging into an account it
+// controls.
+func (l *LoginLimiter) Reset(ctx context.Context, email string) error {
+	return l.attempts.Reset(ctx, emailKey(email))
+}
+
+// delay returns how long to block a key after its nth failure: doubling
+// from BackoffBase up to BackoffMax, and LockoutDuration once the limit is
+// reached.
+func (l *LoginLimiter) delay(failures, maxFailures int) time.Duration {
+	if failures >= maxFailures {
+		return l.cfg.LockoutDuration
+	}
+	delay := l.cfg.BackoffBase
+	for i := 1; i < failures && delay < l.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > l.cfg.BackoffMax {
+		delay = l.cfg.BackoffMax
+	}
+	return delay
+}
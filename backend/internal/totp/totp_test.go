@@ -0,0 +1,101 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the ASCII key "12345678901234567890" used by the RFC 4226
+// and RFC 6238 test vectors, base32 encoded.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateRFC4226(t *testing.T) {
+	// RFC 4226 Appendix D.
+	want := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+	key := []byte("12345678901234567890")
+	for counter, code := range want {
+		if got := generate(key, int64(counter)); got != code {
+			t.Errorf("generate(counter %d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestValidateRFC6238(t *testing.T) {
+	// RFC 6238 Appendix B, SHA-1. The RFC lists eight digit codes; six
+	// digit codes are their last six digits.
+	vectors := []struct {
+		unix int64
+		code string
+		step int64
+	}{
+		{59, "287082", 1},
+		{1111111109, "081804", 37037036},
+		{1111111111, "050471", 37037037},
+		{1234567890, "005924", 41152263},
+		{2000000000, "279037", 66666666},
+		{20000000000, "353130", 666666666},
+	}
+	for _, v := range vectors {
+		step, ok := Validate(rfcSecret, v.code, time.Unix(v.unix, 0))
+		if !ok {
+			t.Errorf("Validate(%s at %d) rejected the code", v.code, v.unix)
+			continue
+		}
+		if step != v.step {
+			t.Errorf("Validate(%s at %d) step = %d, want %d", v.code, v.unix, step, v.step)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	key, err := encoding.DecodeString(rfcSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const current = 1000
+	now := time.Unix(current*int64(Period/time.Second)+7, 0)
+
+	for offset := int64(-3); offset <= 3; offset++ {
+		code := generate(key, current+offset)
+		step, ok := Validate(rfcSecret, code, now)
+		within := offset >= -Skew && offset <= Skew
+		if ok != within {
+			t.Errorf("code from offset %d: accepted = %v, want %v", offset, ok, within)
+		}
+		if ok && step != current+offset {
+			t.Errorf("code from offset %d: step = %d, want %d", offset, step, current+offset)
+		}
+	}
+}
+
+func TestValidateRejectsMalformed(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, tc := range []struct{ secret, code string }{
+		{rfcSecret, "28708"},
+		{rfcSecret, "2870822"},
+		{rfcSecret, "abcdef"},
+		{"not base32!", "287082"},
+	} {
+		if _, ok := Validate(tc.secret, tc.code, now); ok {
+			t.Errorf("Validate(%q, %q) accepted", tc.secret, tc.code)
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil || len(key) != secretSize {
+		t.Fatalf("GenerateSecret() = %q: decodes to %d bytes, err %v", secret, len(key), err)
+	}
+	code := generate(key, time.Now().Unix()/int64(Period/time.Second))
+	if _, ok := Validate(secret, code, time.Now()); !ok {
+		t.Error("code for a generated secret was rejected")
+	}
+}
@@ -0,0 +1,195 @@
+// Package validation checks request payloads against `validate` struct tags
+// and renders failures as a consistent JSON error body.
+//
+// Supported rules, separated by commas:
+//
+//	required   string must be non-blank, slices non-empty
+//	email      string must look like an email address
+//	min=N      minimum string length (in characters) or slice length
+//	max=N      maximum string length (in characters) or slice length
+//	oneof=a b  string must equal one of the space separated values
+//
+// Rules on a pointer field apply to the value it points to, and nil pointers
+// are skipped. This suits partial updates where absent fields are left alone.
+package validation
+
+import (
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Stable error codes returned to clients.
+const (
+	CodeValidationFailed = "validation_failed"
+	CodeInvalidBody      = "invalid_body"
+
+	CodeRequired     = "required"
+	CodeInvalidEmail = "invalid_email"
+	CodeTooShort     = "too_short"
+	CodeTooLong      = "too_long"
+	CodeInvalidValue = "invalid_value"
+	CodeInvalidType  = "invalid_type"
+	CodeImmutable    = "immutable"
+	CodeUnknownField = "unknown_field"
+	CodeNotFound     = "not_found"
+
+	CodeInvalidTransition = "invalid_transition"
+)
+
+// FieldError describes why a single field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors is the list of field errors found in a payload.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Field + ": " + fieldError.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Add appends a field error.
+func (e *Errors) Add(field, code, message string) {
+	*e = append(*e, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Struct validates every exported field of v, which must be a struct or a
+// pointer to one. Field names in the result follow the json tag.
+func Struct(v interface{}) Errors {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	valueType := value.Type()
+
+	var errs Errors
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		rules := field.Tag.Get("validate")
+		if rules == "" || !field.IsExported() {
+			continue
+		}
+		checkField(&errs, jsonName(field), value.Field(i), rules)
+	}
+	return errs
+}
+
+func checkField(errs *Errors, name string, value reflect.Value, rules string) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		key, arg, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			if isEmpty(value) {
+				errs.Add(name, CodeRequired, "is required")
+				return
+			}
+		case "email":
+			if value.Kind() == reflect.String && value.String() != "" && !isEmail(value.String()) {
+				errs.Add(name, CodeInvalidEmail, "must be a valid email address")
+				return
+			}
+		case "min":
+			n, _ := strconv.Atoi(arg)
+			if length(value) < n {
+				errs.Add(name, CodeTooShort, sizeMessage(value, "at least", arg))
+				return
+			}
+		case "max":
+			n, _ := strconv.Atoi(arg)
+			if length(value) > n {
+				errs.Add(name, CodeTooLong, sizeMessage(value, "at most", arg))
+				return
+			}
+		case "oneof":
+			if value.Kind() == reflect.String && value.String() != "" && !contains(strings.Fields(arg), value.String()) {
+				errs.Add(name, CodeInvalidValue, "must be one of: "+strings.Join(strings.Fields(arg), ", "))
+				return
+			}
+		}
+	}
+}
+
+// Respond writes a 400 response describing errs.
+func Respond(c *fiber.Ctx, errs Errors) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error":  "Validation failed",
+		"code":   CodeValidationFailed,
+		"fields": errs,
+	})
+}
+
+// InvalidBody writes a 400 response for a body that could not be parsed.
+func InvalidBody(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "Invalid request body",
+		"code":  CodeInvalidBody,
+	})
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	case reflect.Interface:
+		return value.IsNil()
+	default:
+		return value.IsZero()
+	}
+}
+
+func length(value reflect.Value) int {
+	switch value.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(value.String())
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len()
+	default:
+		return 0
+	}
+}
+
+func sizeMessage(value reflect.Value, bound, n string) string {
+	if value.Kind() == reflect.String {
+		return "must be " + bound + " " + n + " characters long"
+	}
+	return "must have " + bound + " " + n + " items"
+}
+
+func isEmail(s string) bool {
+	address, err := mail.ParseAddress(s)
+	return err == nil && address.Address == s && strings.Contains(s, "@")
+}
+
+func contains(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+// Package workflow describes the statuses a task moves through and which
+// moves between them are allowed.
+package workflow
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow is a set of statuses and the transitions between them. Staying
+// in the same status is always allowed.
+type Workflow struct {
+	// Initial is the status given to new tasks that do not specify one.
+	Initial string `json:"initial" yaml:"initial"`
+	// Final is the status of a completed task.
+	Final string `json:"final" yaml:"final"`
+	// Transitions maps each status to the statuses it may move to. Every
+	// status must appear as a key, even if it has no outgoing transitions.
+	Transitions map[string][]string `json:"transitions" yaml:"transitions"`
+}
+
+// Default is todo → in_progress → review → done, with shortcuts for
+// completing and reopening tasks.
+func Default() Workflow {
+	return Workflow{
+		Initial: "todo",
+		Final:   "done",
+		Transitions: map[string][]string{
+			"todo":        {"in_progress", "done"},
+			"in_progress": {"todo", "review", "done"},
+			"review":      {"in_progress", "done"},
+			"done":        {"todo", "in_progress"},
+		},
+	}
+}
+
+// UnmarshalYAML replaces the whole workflow rather than merging into the
+// default, so a configured workflow never inherits default statuses.
+func (w *Workflow) UnmarshalYAML(node *yaml.Node) error {
+	type plain Workflow
+	var decoded plain
+	if err := node.Decode(&decoded); err != nil {
+		return err
+	}
+	*w = Workflow(decoded)
+	return nil
+}
+
+// Valid reports whether status belongs to the workflow.
+func (w Workflow) Valid(status string) bool {
+	_, ok := w.Transitions[status]
+	return ok
+}
+
+// Allowed reports whether a task may move from one status to another.
+func (w Workflow) Allowed(from, to string) bool {
+	if from == to {
+		return w.Valid(to)
+	}
+	for _, next := range w.Transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Statuses returns every status in the workflow, sorted by name.
+func (w Workflow) Statuses() []string {
+	statuses := make([]string, 0, len(w.Transitions))
+	for status := range w.Transitions {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// Validate checks that the workflow is internally consistent.
+func (w Workflow) Validate() error {
+	if len(w.Transitions) == 0 {
+		return fmt.Errorf("workflow has no statuses")
+	}
+	if !w.Valid(w.Initial) {
+		return fmt.Errorf("workflow initial status %q is not a workflow status", w.Initial)
+	}
+	if !w.Valid(w.Final) {
+		return fmt.Errorf("workflow final status %q is not a workflow status", w.Final)
+	}
+	for from, targets := range w.Transitions {
+		for _, to := range targets {
+			if !w.Valid(to) {
+				return fmt.Errorf("workflow transition %s → %s targets an unknown status", from, to)
+			}
+		}
+	}
+	return nil
+}
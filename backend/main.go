@@ -1,44 +1,115 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/joho/godotenv"
+	"backend/internal/config"
+	"backend/internal/controllers"
 	"backend/internal/initialize"
 	"backend/internal/database"
+	"backend/internal/jobs"
+	"backend/internal/mail"
 	"backend/internal/routes"
-	// "github.com/gofiber/fiber/v2/middleware/csrf"
+	"backend/internal/storage"
+	"backend/internal/store"
 	// "github.com/gofiber/fiber/v2/middleware/helmet"
 )
 
 func main() {
-	fmt.Println("Hello")
-	err := godotenv.Load()
+	cfg, err := config.Load()
 	if err != nil {
-        log.Println("Error loading .env file")
-    }
-	database.Connect()
-	initialize.InitializePermissionsAndRoles()
-	app := fiber.New()
+		log.Fatal(err)
+	}
+	database.Connect(cfg.Database)
+	database.EnsureIndexes()
+	database.MigrateTasks(cfg.Tasks.Workflow.Initial, cfg.Tasks.Workflow.Final)
+	database.MigrateUsers()
+	stores := store.NewMongoStores(database.GetDatabase())
+	if cfg.Login.Store == config.LoginStoreMemory {
+		stores.Logins = store.NewMemoryLoginAttemptStore()
+	}
+	initialize.InitializePermissionsAndRoles(stores)
+	initialize.MigrateProjects(stores)
+	files, err := storage.NewLocal(cfg.Attachments.Dir)
+	if err != nil {
+		log.Fatal("Failed to prepare attachment storage:", err)
+	}
+	app := fiber.New(fiber.Config{
+		// Leave room for the multipart framing around the largest upload.
+		BodyLimit: int(cfg.Attachments.MaxSize) + 1<<20,
+		// c.IP() reads ProxyHeader only on requests from a trusted proxy.
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: len(cfg.Server.TrustedProxies) > 0,
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
+	})
     app.Use(cors.New(cors.Config{
-		AllowOrigins: "http://localhost:5173" ,  
-		AllowMethods: "GET,POST,PUT,DELETE",  
-		AllowHeaders: "Content-Type,Authorization",  
+		AllowOrigins: strings.Join(cfg.Server.CORSOrigins, ","),
+		AllowMethods: "GET,POST,PUT,PATCH,DELETE",  
+		AllowHeaders: "Content-Type,Authorization,X-CSRF-Token",  
 		AllowCredentials: true,  
 	}))
 	
-	// app.Use(csrf.New())
+	// CSRF tokens are checked by the auth middleware, and only for requests
+	// authenticated by cookies; see middleware.ValidCSRF.
 	// app.Use(helmet.New())
 	
-	routes.Stepup(app)
+	health := controllers.NewHealthController(database.Ping)
+	routes.Stepup(app, stores, files, mail.New(cfg.Mail), health, cfg)
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		jobs.RunTaskPurge(jobsCtx, stores, files, cfg.Tasks)
+	}()
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(":" + cfg.Server.Port)
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-listenErr:
+		log.Fatal(err)
+	case sig := <-signals:
+		log.Printf("Received %s, shutting down", sig)
+	}
+
+	// Fail readiness first and keep serving while load balancers notice, so
+	// that requests are not routed to a server that has stopped listening.
+	health.Drain()
+	time.Sleep(cfg.Server.DrainPeriod)
 
-	port := os.Getenv("PORT")
+	stopJobs()
 
-	fmt.Println(port)
+	// Stop accepting connections and let in-flight requests finish before
+	// the Mongo client they depend on is closed.
+	if err := app.ShutdownWithTimeout(cfg.Server.ShutdownTimeout); err != nil {
+		log.Printf("Failed to shut down HTTP server: %v", err)
+	}
+	select {
+	case <-jobsDone:
+	case <-time.After(cfg.Server.ShutdownTimeout):
+		log.Println("Timed out waiting for background jobs to stop")
+	}
 
-	log.Fatal(app.Listen(":" + port))
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := database.Disconnect(ctx); err != nil {
+		log.Printf("Failed to disconnect from MongoDB: %v", err)
+	}
+	log.Println("Server stopped")
 }
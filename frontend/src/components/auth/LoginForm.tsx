@@ -1,4 +1,4 @@
-import { useState } from 'react';
+import { useState, type FormEvent } from 'react';
 import { useNavigate } from 'react-router-dom';
 import { useForm } from 'react-hook-form';
 import { zodResolver } from '@hookform/resolvers/zod';
@@ -23,6 +23,10 @@ const loginSchema = z.object({
 
 export function LoginForm() {
   const [loading, setLoading] = useState(false);
+  // Set when the password was accepted but the account uses two-factor
+  // authentication; the code is then exchanged for the session.
+  const [mfaToken, setMfaToken] = useState<string | null>(null);
+  const [mfaCode, setMfaCode] = useState('');
   const { toast } = useToast();
   const navigate = useNavigate();
   const { setUser } = useAuth(); // Zustand action to set the authenticated user
@@ -54,6 +58,50 @@ export function LoginForm() {
     }
   };
 
+  const finishLogin = async (data: { message: string }) => {
+    toast({ title: 'Login Successful', description: data.message });
+
+    // Fetch user data after login
+    await fetchUserData();
+
+    // Redirect to tasks page
+    navigate('/tasks');
+  };
+
+  const onSubmitCode = async (event: FormEvent) => {
+    event.preventDefault();
+    setLoading(true);
+    try {
+      const response = await fetch('http://localhost:8000/api/login/mfa', {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        credentials: 'include',
+        body: JSON.stringify({ mfa_token: mfaToken, code: mfaCode }),
+      });
+
+      if (!response.ok) {
+        const { error, code } = await response.json().catch(() => ({}));
+        if (code === 'invalid_mfa_challenge') {
+          setMfaToken(null);
+          setMfaCode('');
+        }
+        throw new Error(error || 'Invalid two-factor code');
+      }
+
+      await finishLogin(await response.json());
+    } catch (error) {
+      toast({
+        title: 'Error',
+        description: error instanceof Error ? error.message : 'An error occurred.',
+        variant: 'destructive',
+      });
+    } finally {
+      setLoading(false);
+    }
+  };
+
   const onSubmit = async (values: z.infer<typeof loginSchema>) => {
     setLoading(true);
     try {
@@ -67,17 +115,16 @@ export function LoginForm() {
       });
 
       if (!response.ok) {
-        throw new Error('Invalid email or password');
+        const { error } = await response.json().catch(() => ({}));
+        throw new Error(error || 'Invalid email or password');
       }
 
-      const { message } = await response.json();
-      toast({ title: 'Login Successful', description: message });
-
-      // Fetch user data after login
-      await fetchUserData();
-
-      // Redirect to tasks page
-      navigate('/tasks');
+      const data = await response.json();
+      if (data.mfa_required) {
+        setMfaToken(data.mfa_token);
+        return;
+      }
+      await finishLogin(data);
     } catch (error) {
       toast({
         title: 'Error',
@@ -89,6 +136,28 @@ export function LoginForm() {
     }
   };
 
+  if (mfaToken) {
+    return (
+      <form onSubmit={onSubmitCode} className="space-y-6">
+        <div className="space-y-2">
+          <label htmlFor="mfa-code" className="text-sm font-medium">
+            Authentication code
+          </label>
+          <Input
+            id="mfa-code"
+            placeholder="123456 or a recovery code"
+            autoComplete="one-time-code"
+            value={mfaCode}
+            onChange={(event) => setMfaCode(event.target.value)}
+          />
+        </div>
+        <Button type="submit" className="w-full" disabled={loading || !mfaCode}>
+          {loading ? 'Verifying...' : 'Verify'}
+        </Button>
+      </form>
+    );
+  }
+
   return (
     <Form {...form}>
       <form onSubmit={form.handleSubmit(onSubmit)} className="space-y-6">
@@ -74,7 +74,7 @@ export function SignUpForm() {
 
       toast({
         title: 'Account created successfully!',
-        description: 'Welcome to Task Manager',
+        description: 'Check your inbox to verify your email address',
       });
 
       navigate('/login'); 
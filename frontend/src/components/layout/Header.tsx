@@ -1,5 +1,5 @@
 import { Button } from '@/components/ui/button';
-import { useAuth } from '@/lib/auth';
+import { csrfHeaders, useAuth } from '@/lib/auth';
 import { useNavigate } from 'react-router-dom';
 import { LogOut, User } from 'lucide-react';
 import {
@@ -20,6 +20,7 @@ export function Header() {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
+          ...csrfHeaders(),
         },
         credentials: 'include',
       });
@@ -20,11 +20,13 @@ import {
   DialogHeader,
   DialogTitle,
 } from '@/components/ui/dialog';
+import { csrfHeaders } from '@/lib/auth';
+import { getProjectTasksUrl } from '@/lib/projects';
 
 const taskSchema = z.object({
   name: z.string().min(1, 'Title is required'),
   description: z.string().min(1, 'Description is required'),
-  status: z.boolean(),
+  status: z.string(),
 });
 
 interface TaskFormProps {
@@ -41,7 +43,7 @@ export function TaskForm({ task, open, onOpenChange }: TaskFormProps) {
     defaultValues: {
       name: task?.name || '',  // Pre-fill with existing task data
       description: task?.description || '',
-      status: task?.status || false,
+      status: task?.status || 'todo',
     },
   });
 
@@ -67,11 +69,11 @@ export function TaskForm({ task, open, onOpenChange }: TaskFormProps) {
     name,
     description,
     status,
-  }: { name: string; description: string; status: boolean }) => {
+  }: { name: string; description: string; status: string }) => {
     try {
-      const response = await fetch('http://localhost:8000/api/tasks', {
+      const response = await fetch(await getProjectTasksUrl(), {
         method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
+        headers: { 'Content-Type': 'application/json', ...csrfHeaders() },
         credentials: 'include',
         body: JSON.stringify({ name, description, status }),
       });
@@ -92,12 +94,12 @@ export function TaskForm({ task, open, onOpenChange }: TaskFormProps) {
 
   const handleUpdateTask = async (
     taskId: string,
-    { name, description, status }: { name: string; description: string; status: boolean }
+    { name, description, status }: { name: string; description: string; status: string }
   ) => {
     try {
-      const response = await fetch(`http://localhost:8000/api/tasks/${taskId}`, {
+      const response = await fetch(`${await getProjectTasksUrl()}/${taskId}`, {
         method: 'PUT',
-        headers: { 'Content-Type': 'application/json' },
+        headers: { 'Content-Type': 'application/json', ...csrfHeaders() },
         credentials: 'include',
         body: JSON.stringify({ name, description, status }),
       });
@@ -5,6 +5,8 @@ import { Checkbox } from '@/components/ui/checkbox';
 import { format } from 'date-fns';
 import { Pencil, Trash2 } from 'lucide-react';
 import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from '@/components/ui/table';
+import { csrfHeaders } from '@/lib/auth';
+import { getProjectTasksUrl } from '@/lib/projects';
 
 interface TaskListProps {
   onEdit: (task: Task) => void;
@@ -16,7 +18,7 @@ export function TaskList({ onEdit }: TaskListProps) {
   useEffect(() => {
     const fetchTasks = async () => {
       try {
-        const response = await fetch('http://localhost:8000/api/tasks', {
+        const response = await fetch(await getProjectTasksUrl(), {
           headers: { 'Content-Type': 'application/json' },
           credentials: 'include',
         });
@@ -27,7 +29,7 @@ export function TaskList({ onEdit }: TaskListProps) {
           id: task._id,
           name: task.name,
           description: task.description,
-          status: !!task.status,
+          status: task.status,
           createdAt: new Date(task.created_at),
           updatedAt: new Date(task.updated_at),
         }));
@@ -40,20 +42,21 @@ export function TaskList({ onEdit }: TaskListProps) {
     fetchTasks();
   }, []);
 
-  const handleToggleComplete = async (taskId: string, currentStatus: boolean) => {
+  const handleToggleComplete = async (taskId: string, currentStatus: string) => {
+    const nextStatus = currentStatus === 'done' ? 'todo' : 'done';
     try {
-      const response = await fetch(`http://localhost:8000/api/tasks/${taskId}`, {
-        method: 'PUT',
-        headers: { 'Content-Type': 'application/json' },
+      const response = await fetch(`${await getProjectTasksUrl()}/${taskId}`, {
+        method: 'PATCH',
+        headers: { 'Content-Type': 'application/json', ...csrfHeaders() },
         credentials: 'include',
-        body: JSON.stringify({ status: !currentStatus }),
+        body: JSON.stringify({ status: nextStatus }),
       });
 
       if (!response.ok) throw new Error('Failed to update task status');
 
       setTasks((prevTasks) =>
         prevTasks.map((task) =>
-          task.id === taskId ? { ...task, status: !currentStatus } : task
+          task.id === taskId ? { ...task, status: nextStatus } : task
         )
       );
     } catch (error) {
@@ -63,9 +66,9 @@ export function TaskList({ onEdit }: TaskListProps) {
 
   const handleDeleteTask = async (taskId: string) => {
     try {
-      const response = await fetch(`http://localhost:8000/api/tasks/${taskId}`, {
+      const response = await fetch(`${await getProjectTasksUrl()}/${taskId}`, {
         method: 'DELETE',
-        headers: { 'Content-Type': 'application/json' },
+        headers: { 'Content-Type': 'application/json', ...csrfHeaders() },
         credentials: 'include',
       });
 
@@ -94,13 +97,13 @@ export function TaskList({ onEdit }: TaskListProps) {
               <TableRow key={task.id}>
                 <TableCell>
                   <Checkbox
-                    checked={!!task.status}
-                    onCheckedChange={() => handleToggleComplete(task.id, !!task.status)}
+                    checked={task.status === 'done'}
+                    onCheckedChange={() => handleToggleComplete(task.id, task.status)}
                   />
                 </TableCell>
                 <TableCell>
                   <div>
-                    <p className={task.status ? 'line-through text-gray-500' : ''}>
+                    <p className={task.status === 'done' ? 'line-through text-gray-500' : ''}>
                       {task.name}
                     </p>
                     <p className="text-sm text-gray-500">{task.description}</p>
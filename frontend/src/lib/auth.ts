@@ -1,5 +1,13 @@
 import { create } from 'zustand';
 
+// csrfHeaders returns the header the API requires on state-changing
+// requests authenticated by the session cookie. The token is issued in the
+// csrf_token cookie at login.
+export function csrfHeaders(): Record<string, string> {
+  const match = document.cookie.match(/(?:^|;\s*)csrf_token=([^;]*)/);
+  return match ? { 'X-CSRF-Token': decodeURIComponent(match[1]) } : {};
+}
+
 interface User {
   id?: string;
   email: string;
@@ -0,0 +1,18 @@
+let currentProjectId: string | null = null;
+
+// Tasks live inside projects. Until the UI lets users switch projects, the
+// first project the user belongs to is used.
+export async function getProjectTasksUrl(): Promise<string> {
+  if (!currentProjectId) {
+    const response = await fetch('http://localhost:8000/api/projects', {
+      headers: { 'Content-Type': 'application/json' },
+      credentials: 'include',
+    });
+    if (!response.ok) throw new Error('Failed to fetch projects');
+
+    const data = await response.json();
+    if (!data.projects.length) throw new Error('You are not a member of any project');
+    currentProjectId = data.projects[0]._id as string;
+  }
+  return `http://localhost:8000/api/projects/${currentProjectId}/tasks`;
+}
@@ -20,7 +20,7 @@ export const useTaskStore = create<TaskState>((set) => ({
           ...task,
           id: crypto.randomUUID(),
           createdAt: new Date(),
-          status: false,
+          status: 'todo',
         },
       ],
     })),
@@ -37,7 +37,7 @@ export const useTaskStore = create<TaskState>((set) => ({
   toggleComplete: (id) =>
     set((state) => ({
       tasks: state.tasks.map((task) =>
-        task.id === id ? { ...task, status: !task.status } : task
+        task.id === id ? { ...task, status: task.status === 'done' ? 'todo' : 'done' } : task
       ),
     })),
   syncTasks: (tasks) =>
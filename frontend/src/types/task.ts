@@ -2,7 +2,7 @@ export interface Task {
     id: string;
     name: string;
     description: string;
-    status: boolean;
+    status: string;
     createdAt?: Date;
     updatedAt?:Date;
   }
\ No newline at end of file